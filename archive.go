@@ -0,0 +1,50 @@
+package s3ReadSeeker
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// archivedError returns an *ObjectArchivedError if the HeadObject result
+// describes an object in an archive storage class or access tier that has
+// not been restored, and nil if o can be read.
+func (o *Object) archivedError(result *s3.HeadObjectOutput) *ObjectArchivedError {
+	storageClass := string(result.StorageClass)
+	switch {
+	case result.StorageClass == types.StorageClassGlacier, result.StorageClass == types.StorageClassDeepArchive:
+	case result.ArchiveStatus != "":
+		// an Intelligent-Tiering object moved to an archive access tier
+		storageClass = string(result.ArchiveStatus)
+	default:
+		return nil
+	}
+	restore := aws.ToString(result.Restore)
+	if strings.Contains(restore, `ongoing-request="false"`) {
+		// a restored copy is available
+		return nil
+	}
+	return &ObjectArchivedError{
+		Bucket:            o.bucketName,
+		Key:               o.key,
+		StorageClass:      storageClass,
+		RestoreInProgress: strings.Contains(restore, `ongoing-request="true"`),
+	}
+}
+
+// invalidObjectStateError turns the InvalidObjectState error of a
+// GetObject of an archived object into an *ObjectArchivedError.
+func (o *Object) invalidObjectStateError(err error) error {
+	var stateErr *types.InvalidObjectState
+	if !errors.As(err, &stateErr) {
+		return err
+	}
+	storageClass := string(stateErr.StorageClass)
+	if stateErr.AccessTier != "" {
+		storageClass = string(stateErr.AccessTier)
+	}
+	return &ObjectArchivedError{Bucket: o.bucketName, Key: o.key, StorageClass: storageClass, Err: err}
+}
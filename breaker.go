@@ -0,0 +1,109 @@
+package s3ReadSeeker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// breaker is the circuit breaker of a member, see WithCircuitBreaker.
+type breaker struct {
+	mu        sync.Mutex
+	failures  int
+	lastErr   error
+	openUntil time.Time
+	// probing is set while the single request allowed after the
+	// cool-down is in flight
+	probing bool
+}
+
+// allow returns a *MemberUnavailableError if the breaker of o is open,
+// failing the request fast. It is only called for reads that issue a
+// request, so that reads served from memory keep working.
+func (o *Object) allow() error {
+	if o.opts.breakerThreshold == 0 {
+		return nil
+	}
+	b := &o.breaker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < o.opts.breakerThreshold {
+		return nil
+	}
+	if b.probing || time.Now().Before(b.openUntil) {
+		return &MemberUnavailableError{Bucket: o.bucketName, Key: o.key, RetryAt: b.openUntil, Err: b.lastErr}
+	}
+	b.probing = true
+	return nil
+}
+
+// record updates the breaker of o with the outcome of a request allowed
+// by allow and issued with ctx. Cancellations, and errors once ctx is
+// done, e.g. past the caller's deadline, say nothing about the member and
+// are not counted.
+func (o *Object) record(ctx context.Context, err error) {
+	if o.opts.breakerThreshold == 0 {
+		return
+	}
+	b := &o.breaker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	switch {
+	case err == nil:
+		b.failures, b.lastErr = 0, nil
+	case ctx.Err() != nil, errors.Is(err, context.Canceled), errors.Is(err, ErrMemberUnavailable):
+	default:
+		b.failures++
+		b.lastErr = err
+		if b.failures >= o.opts.breakerThreshold {
+			b.openUntil = time.Now().Add(o.opts.breakerCooldown)
+		}
+	}
+}
+
+// MemberHealth is the circuit breaker state of a member, see
+// WithCircuitBreaker.
+type MemberHealth struct {
+	// Index is the position of the member in Members.
+	Index  int
+	Bucket string
+	Key    string
+	// ConsecutiveFailures counts the failed requests since the last
+	// successful one, and LastError is the last of their errors.
+	ConsecutiveFailures int
+	LastError           error
+	// Unavailable is set while reads of the member fail fast, until
+	// RetryAt.
+	Unavailable bool
+	RetryAt     time.Time
+}
+
+// Health returns the circuit breaker state of the members whose last
+// request failed. It is empty without WithCircuitBreaker.
+func (s *S3ReadSeeker) Health() []MemberHealth {
+	var health []MemberHealth
+	if s.opts.breakerThreshold == 0 {
+		return health
+	}
+	members, _ := s.members()
+	now := time.Now()
+	for i, obj := range members {
+		b := &obj.breaker
+		b.mu.Lock()
+		if b.failures > 0 {
+			health = append(health, MemberHealth{
+				Index:               i,
+				Bucket:              obj.bucketName,
+				Key:                 obj.key,
+				ConsecutiveFailures: b.failures,
+				LastError:           b.lastErr,
+				Unavailable:         b.failures >= s.opts.breakerThreshold && now.Before(b.openUntil),
+				RetryAt:             b.openUntil,
+			})
+		}
+		b.mu.Unlock()
+	}
+	return health
+}
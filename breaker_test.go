@@ -0,0 +1,140 @@
+package s3ReadSeeker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+func TestBreakerSparesReadsFromMemory(t *testing.T) {
+	f := newFakeS3()
+	keys, all := fakeMembers(f, 1000)
+	var failing atomic.Bool
+	f.onGet = func(context.Context, *s3.GetObjectInput) error {
+		if failing.Load() {
+			return &smithy.GenericAPIError{Code: "AccessDenied"}
+		}
+		return nil
+	}
+	rs, err := NewS3ReadSeeker(f, testBucket, keys, WithCircuitBreaker(2, time.Hour), WithBlockCache(100, 1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	p := make([]byte, 50)
+	if _, err := rs.ReadAt(p, 0); err != nil {
+		t.Fatal(err)
+	}
+	failing.Store(true)
+	for i := 0; i < 2; i++ {
+		if _, err := rs.ReadAt(p, 500); !errors.Is(err, ErrAccessDenied) {
+			t.Fatalf("ReadAt of a failing member = %v, want ErrAccessDenied", err)
+		}
+	}
+	gets := f.getCount()
+	if _, err := rs.ReadAt(p, 500); !errors.Is(err, ErrMemberUnavailable) {
+		t.Fatalf("ReadAt with the breaker open = %v, want ErrMemberUnavailable", err)
+	}
+	if got := f.getCount(); got != gets {
+		t.Errorf("ReadAt with the breaker open issued %d GetObjects", got-gets)
+	}
+	// the cached block still reads
+	if _, err := rs.ReadAt(p, 10); err != nil || !bytes.Equal(p, all[10:60]) {
+		t.Errorf("ReadAt of a cached block with the breaker open = %v", err)
+	}
+}
+
+func TestBreakerIgnoresCallerDeadline(t *testing.T) {
+	f := newFakeS3()
+	keys, _ := fakeMembers(f, 1000)
+	f.onGet = func(ctx context.Context, _ *s3.GetObjectInput) error {
+		return sleep(ctx, time.Minute)
+	}
+	rs, err := NewS3ReadSeeker(f, testBucket, keys, WithCircuitBreaker(1, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		_, err := rs.ReadAtContext(ctx, make([]byte, 10), 0)
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("ReadAtContext past its deadline = %v, want context.DeadlineExceeded", err)
+		}
+	}
+	if health := rs.Health(); len(health) != 0 {
+		t.Errorf("Health() = %+v, want no failures", health)
+	}
+}
+
+func TestBreakerCoversWriteTo(t *testing.T) {
+	f := newFakeS3()
+	keys, _ := fakeMembers(f, 1000)
+	f.onGet = func(context.Context, *s3.GetObjectInput) error {
+		return &smithy.GenericAPIError{Code: "AccessDenied"}
+	}
+	rs, err := NewS3ReadSeeker(f, testBucket, keys, WithCircuitBreaker(2, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	for i := 0; i < 2; i++ {
+		if _, err := rs.WriteTo(io.Discard); !errors.Is(err, ErrAccessDenied) {
+			t.Fatalf("WriteTo of a failing member = %v, want ErrAccessDenied", err)
+		}
+	}
+	gets := f.getCount()
+	if _, err := rs.WriteTo(io.Discard); !errors.Is(err, ErrMemberUnavailable) {
+		t.Fatalf("WriteTo with the breaker open = %v, want ErrMemberUnavailable", err)
+	}
+	if got := f.getCount(); got != gets {
+		t.Errorf("WriteTo with the breaker open issued %d GetObjects", got-gets)
+	}
+}
+
+func TestBreakerCountsSharedFetchOnce(t *testing.T) {
+	const readers = 3
+	f := newFakeS3()
+	keys, _ := fakeMembers(f, 1000)
+	var rs *S3ReadSeeker
+	f.onGet = func(context.Context, *s3.GetObjectInput) error {
+		// fail only once every reader has joined the fetch
+		for rs.Stats().DedupedRequests < readers-1 {
+			time.Sleep(time.Millisecond)
+		}
+		return &smithy.GenericAPIError{Code: "AccessDenied"}
+	}
+	var err error
+	rs, err = NewS3ReadSeeker(f, testBucket, keys, WithDeduplication(), WithCircuitBreaker(readers, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	errs := make(chan error, readers)
+	for i := 0; i < readers; i++ {
+		go func() {
+			_, err := rs.ReadAt(make([]byte, 10), 0)
+			errs <- err
+		}()
+	}
+	for i := 0; i < readers; i++ {
+		if err := <-errs; !errors.Is(err, ErrAccessDenied) {
+			t.Fatalf("ReadAt of a failing member = %v, want ErrAccessDenied", err)
+		}
+	}
+	if got := f.getCount(); got != 1 {
+		t.Fatalf("GetObjects = %d, want 1 shared by all readers", got)
+	}
+	health := rs.Health()
+	if len(health) != 1 || health[0].ConsecutiveFailures != 1 || health[0].Unavailable {
+		t.Errorf("Health() = %+v, want a single failure", health)
+	}
+}
@@ -0,0 +1,38 @@
+package s3ReadSeeker
+
+import (
+	"context"
+	"fmt"
+)
+
+// Bytes returns the whole concatenated stream, fetching each member with a
+// single GetObject and up to n members at a time with
+// WithMaxConcurrency(n), or DefaultHeadConcurrency otherwise. If maxBytes
+// is positive and the stream is longer, Bytes fails without fetching
+// anything. It does not use or move the current offset.
+func (s *S3ReadSeeker) Bytes(ctx context.Context, maxBytes int64) ([]byte, error) {
+	if s.closed.Load() {
+		return nil, ErrClosed
+	}
+	ctx, stop := s.bind(ctx)
+	defer stop()
+	if err := s.resolveAll(ctx); err != nil {
+		return nil, err
+	}
+	members, ends := s.resolvedMembers()
+	size := totalSize(ends)
+	if maxBytes > 0 && size > maxBytes {
+		return nil, fmt.Errorf("stream of %d bytes exceeds limit of %d bytes", size, maxBytes)
+	}
+	concurrency := s.opts.readConcurrency
+	if concurrency < 1 {
+		concurrency = DefaultHeadConcurrency
+	}
+	data := make([]byte, size)
+	n, err := readPartsConcurrently(ctx, planReads(members, ends, data, 0), concurrency)
+	s.stats.bytesReturned.Add(int64(n))
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
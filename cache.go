@@ -0,0 +1,146 @@
+package s3ReadSeeker
+
+import (
+	"container/list"
+	"context"
+	"io"
+	"sync"
+)
+
+// DefaultCacheBlockSize is the size of the blocks kept by WithRangeCache.
+// Blocks never span members: the last block of a member is cut short at
+// its end.
+const DefaultCacheBlockSize = 1 << 20
+
+// cacheKey identifies a block of a member. Blocks are numbered from the
+// member's start, so members trimmed to different starts of the same
+// object keep separate blocks.
+type cacheKey struct {
+	bucket    string
+	key       string
+	versionId string
+	start     int64
+	block     int64
+}
+
+type cacheEntry struct {
+	key  cacheKey
+	data []byte
+}
+
+// blockCache is an LRU cache of member blocks bounded by the total size of
+// the cached data. It is safe for concurrent use.
+type blockCache struct {
+	blockSize int64
+	maxBytes  int64
+
+	mu    sync.Mutex
+	size  int64
+	ll    *list.List
+	items map[cacheKey]*list.Element
+}
+
+func newBlockCache(blockSize, maxBytes int64) *blockCache {
+	return &blockCache{
+		blockSize: blockSize,
+		maxBytes:  maxBytes,
+		ll:        list.New(),
+		items:     make(map[cacheKey]*list.Element),
+	}
+}
+
+func (c *blockCache) get(key cacheKey) []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		return nil
+	}
+	c.ll.MoveToFront(e)
+	return e.Value.(*cacheEntry).data
+}
+
+func (c *blockCache) add(key cacheKey, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&cacheEntry{key: key, data: data})
+	c.size += int64(len(data))
+	for c.size > c.maxBytes {
+		e := c.ll.Back()
+		entry := e.Value.(*cacheEntry)
+		c.ll.Remove(e)
+		delete(c.items, entry.key)
+		c.size -= int64(len(entry.data))
+	}
+}
+
+// reset drops all blocks.
+func (c *blockCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ll.Init()
+	clear(c.items)
+	c.size = 0
+}
+
+func (o *Object) cacheKey(block int64) cacheKey {
+	return cacheKey{bucket: o.bucketName, key: o.key, versionId: o.versionId, start: o.start, block: block}
+}
+
+// readCached serves a read from the blocks covering [off, off+len(p)),
+// fetching each run of missing blocks with a single GetObject.
+func (o *Object) readCached(ctx context.Context, p []byte, off int64) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	bs := o.cache.blockSize
+	first := off / bs
+	last := (off + int64(len(p)) - 1) / bs
+	blocks := make([][]byte, last-first+1)
+	for i := range blocks {
+		blocks[i] = o.cache.get(o.cacheKey(first + int64(i)))
+		if blocks[i] != nil {
+			o.stats.cacheHits.Add(1)
+		} else {
+			o.stats.cacheMisses.Add(1)
+		}
+	}
+	for i := 0; i < len(blocks); {
+		if blocks[i] != nil {
+			i++
+			continue
+		}
+		j := i
+		for j < len(blocks) && blocks[j] == nil {
+			j++
+		}
+		start := (first + int64(i)) * bs
+		end := min((first+int64(j))*bs, o.size)
+		if end <= start {
+			return 0, &SizeMismatchError{Bucket: o.bucketName, Key: o.key, Size: o.size, Offset: start, Err: io.ErrUnexpectedEOF}
+		}
+		buf := make([]byte, end-start)
+		if _, err := o.fetch(ctx, buf, start); err != nil {
+			return 0, err
+		}
+		for k := i; k < j; k++ {
+			blockStart := int64(k-i) * bs
+			blocks[k] = buf[blockStart:min(blockStart+bs, int64(len(buf)))]
+			o.cache.add(o.cacheKey(first+int64(k)), blocks[k])
+		}
+		i = j
+	}
+	for pos := off; n < len(p); pos = off + int64(n) {
+		block := blocks[pos/bs-first]
+		blockOff := pos % bs
+		if blockOff >= int64(len(block)) {
+			return n, &SizeMismatchError{Bucket: o.bucketName, Key: o.key, Size: o.size, Offset: pos, Err: io.ErrUnexpectedEOF}
+		}
+		n += copy(p[n:], block[blockOff:])
+	}
+	return n, nil
+}
@@ -0,0 +1,31 @@
+package s3ReadSeeker
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestMinFetchSize(t *testing.T) {
+	const size, fetchSize = 10 << 20, 256 << 10
+	f := newFakeS3()
+	keys, all := fakeMembers(f, size)
+	rs, err := NewS3ReadSeeker(f, testBucket, keys, WithMinFetchSize(fetchSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	// like archive/tar, which reads in 512-byte blocks
+	p := make([]byte, 512)
+	for off := 0; off < size; off += len(p) {
+		if _, err := io.ReadFull(rs, p); err != nil {
+			t.Fatalf("Read at %d = %v", off, err)
+		}
+		if !bytes.Equal(p, all[off:off+len(p)]) {
+			t.Fatalf("Read at %d read wrong bytes", off)
+		}
+	}
+	if got := f.getCount(); got != size/fetchSize {
+		t.Errorf("GetObjects = %d, want %d", got, size/fetchSize)
+	}
+}
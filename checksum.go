@@ -0,0 +1,30 @@
+package s3ReadSeeker
+
+import (
+	"io"
+	"strings"
+)
+
+// checksumMismatch is part of the error the SDK returns when a response
+// body does not match its checksum. The error type is not exported.
+const checksumMismatch = "checksum did not match"
+
+// verifyBody reads the rest of a GetObject body covering the whole of o.
+// With WithChecksumValidation the SDK verifies the checksum once the body
+// is read to its end, which a read of exactly o.size bytes stops short of.
+func (o *Object) verifyBody(body io.Reader, byteRange string) error {
+	if !o.opts.checksumValidation {
+		return nil
+	}
+	_, err := io.Copy(io.Discard, body)
+	return o.checksumError(err, byteRange)
+}
+
+// checksumError turns a checksum mismatch reported by the SDK for a
+// GetObject of byteRange into a *ChecksumMismatchError.
+func (o *Object) checksumError(err error, byteRange string) error {
+	if err == nil || !o.opts.checksumValidation || !strings.Contains(err.Error(), checksumMismatch) {
+		return err
+	}
+	return &ChecksumMismatchError{Bucket: o.bucketName, Key: o.key, Range: byteRange, Err: err}
+}
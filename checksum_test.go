@@ -0,0 +1,61 @@
+package s3ReadSeeker
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// corruptBody returns body with its first byte flipped. Like the SDK with
+// ChecksumMode enabled, it fails at the end of the body of a whole object,
+// whose full-object checksum can be validated.
+func corruptBody(in *s3.GetObjectInput, body io.Reader) io.Reader {
+	data, _ := io.ReadAll(body)
+	data[0] ^= 0xff
+	if in.ChecksumMode != types.ChecksumModeEnabled || in.Range != nil {
+		return bytes.NewReader(data)
+	}
+	return io.MultiReader(bytes.NewReader(data), iotest.ErrReader(errors.New("checksum did not match: algorithm CRC32, expect AAAAAA==, actual BBBBBB==")))
+}
+
+func TestChecksumMismatch(t *testing.T) {
+	f := newFakeS3()
+	keys, all := fakeMembers(f, 100, 100)
+	f.wrapBody = func(in *s3.GetObjectInput, body io.Reader, n int64) io.Reader {
+		if aws.ToString(in.Key) != keys[1] {
+			return body
+		}
+		return corruptBody(in, body)
+	}
+	rs, err := NewS3ReadSeeker(f, testBucket, keys, WithChecksumValidation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	var buf bytes.Buffer
+	_, err = rs.WriteTo(&buf)
+	var sumErr *ChecksumMismatchError
+	if !errors.Is(err, ErrChecksumMismatch) || !errors.As(err, &sumErr) || sumErr.Key != keys[1] || sumErr.Range != "" {
+		t.Fatalf("WriteTo of a corrupted member = %v, want a *ChecksumMismatchError for %s", err, keys[1])
+	}
+	if !bytes.Equal(buf.Bytes()[:100], all[:100]) {
+		t.Error("WriteTo wrote the intact member wrongly")
+	}
+	for _, in := range f.gets {
+		if in.ChecksumMode != types.ChecksumModeEnabled {
+			t.Errorf("GetObject of %s without ChecksumMode", aws.ToString(in.Key))
+		}
+	}
+
+	// a ranged read has no checksum to validate and falls back to none
+	p := make([]byte, 10)
+	if _, err := rs.ReadAt(p, 100); err != nil {
+		t.Errorf("ranged ReadAt = %v, want no validation", err)
+	}
+}
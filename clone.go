@@ -0,0 +1,42 @@
+package s3ReadSeeker
+
+import (
+	"context"
+)
+
+// Clone returns a new seeker over the same members with its own offset,
+// starting at 0, without any HeadObject calls for the sizes s has already
+// resolved. The clone shares the client, options, range cache, request
+// limits and Stats of s, but is otherwise independent: AppendKeys,
+// Refresh and Reset on either do not affect the other, and closing
+// either leaves the other usable. The clone's stored context carries the
+// values of that of s but not its cancellation, see SetContext.
+func (s *S3ReadSeeker) Clone() *S3ReadSeeker {
+	members, _ := s.members()
+	clone := &S3ReadSeeker{
+		client:        s.client,
+		bucketName:    s.bucketName,
+		objectMembers: make([]*Object, len(members)),
+		shared:        s.shared,
+	}
+	// members may be being resolved, so only their settled state under
+	// resolveMu is copied
+	s.resolveMu.Lock()
+	for i, obj := range members {
+		clone.objectMembers[i] = obj.clone()
+	}
+	s.resolveMu.Unlock()
+	clone.computeSize()
+	clone.ctx, clone.cancel = context.WithCancel(context.WithoutCancel(s.context()))
+	clone.lifetime, clone.endLifetime = context.WithCancel(context.Background())
+	return clone
+}
+
+// clone returns a copy of o for Clone, without its open body, in-memory
+// data and circuit breaker state.
+func (o *Object) clone() *Object {
+	c := o.refreshed()
+	c.size, c.etag, c.lastModified = o.size, o.etag, o.lastModified
+	c.resolved, c.archived = o.resolved, o.archived
+	return c
+}
@@ -0,0 +1,60 @@
+package s3ReadSeeker
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"testing"
+)
+
+func TestClonesReadDisjointRegionsConcurrently(t *testing.T) {
+	const clones = 8
+	f := newFakeS3()
+	keys, all := fakeMembers(f, 3000, 1, 4000, 0, 2999)
+	rs, err := NewS3ReadSeeker(f, testBucket, keys, WithReadAheadSize(256))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	heads := f.headCount()
+	region := len(all) / clones
+	var wg sync.WaitGroup
+	errs := make(chan error, clones)
+	for i := 0; i < clones; i++ {
+		c := rs.Clone()
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer c.Close()
+			off := int64(i * region)
+			if _, err := c.Seek(off, io.SeekStart); err != nil {
+				errs <- err
+				return
+			}
+			got := make([]byte, region)
+			if _, err := io.ReadFull(c, got); err != nil {
+				errs <- err
+				return
+			}
+			if !bytes.Equal(got, all[off:off+int64(region)]) {
+				t.Errorf("clone %d read wrong bytes at %d", i, off)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if got := f.headCount(); got != heads {
+		t.Errorf("clones issued %d HeadObjects", got-heads)
+	}
+	// the clones leave the offset of the original alone
+	if off, _ := rs.Seek(0, io.SeekCurrent); off != 0 {
+		t.Errorf("offset of the original = %d, want 0", off)
+	}
+	got, err := io.ReadAll(rs)
+	if err != nil || !bytes.Equal(got, all) {
+		t.Fatalf("ReadAll of the original after its clones closed = %d bytes, %v", len(got), err)
+	}
+}
@@ -0,0 +1,29 @@
+package s3ReadSeeker
+
+// Consistency selects how reads detect that a member was overwritten after
+// its size was resolved, see WithConsistency.
+type Consistency int
+
+const (
+	// ConsistencyNone reads whatever content a key holds at the time.
+	ConsistencyNone Consistency = iota
+	// ConsistencyETag sends If-Match with the ETag of the member. It is
+	// the default.
+	ConsistencyETag
+	// ConsistencyLastModified sends If-Unmodified-Since with the last
+	// modification time of the member, for stores whose ETags are not
+	// stable, e.g. for multipart objects.
+	ConsistencyLastModified
+)
+
+// pinned reports whether GetObjects of o carry a precondition that fails
+// if o was overwritten.
+func (o *Object) pinned() bool {
+	switch o.opts.consistency {
+	case ConsistencyETag:
+		return o.etag != ""
+	case ConsistencyLastModified:
+		return !o.lastModified.IsZero()
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package s3ReadSeeker
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// consistencyModes are the modification checks the tests below share.
+var consistencyModes = []struct {
+	name string
+	opt  Option
+	// check verifies the error for a member that had old when resolved
+	check func(t *testing.T, err *ObjectModifiedError, old *fakeObject)
+}{
+	{"ETag", WithConsistency(ConsistencyETag), func(t *testing.T, err *ObjectModifiedError, old *fakeObject) {
+		if err.ETag != old.etag || !err.LastModified.IsZero() {
+			t.Errorf("ETag = %q, LastModified = %s, want %q and none", err.ETag, err.LastModified, old.etag)
+		}
+	}},
+	{"LastModified", WithConsistency(ConsistencyLastModified), func(t *testing.T, err *ObjectModifiedError, old *fakeObject) {
+		if !err.LastModified.Equal(old.lastModified) || err.ETag != "" {
+			t.Errorf("LastModified = %s, ETag = %q, want %s and none", err.LastModified, err.ETag, old.lastModified)
+		}
+	}},
+}
+
+func TestModifiedMemberDetected(t *testing.T) {
+	for _, mode := range consistencyModes {
+		t.Run(mode.name, func(t *testing.T) {
+			f := newFakeS3()
+			keys, all := fakeMembers(f, 100, 100)
+			rs, err := NewS3ReadSeeker(f, testBucket, keys, mode.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rs.Close()
+			p := make([]byte, 150)
+			if _, err := rs.ReadAt(p, 0); err != nil || !bytes.Equal(p, all[:150]) {
+				t.Fatalf("ReadAt before the overwrite = %v", err)
+			}
+			old, _ := f.object(testBucket, keys[1])
+			f.put(keys[1], bytes.Repeat([]byte{'x'}, 100))
+
+			// the unmodified member still reads
+			if _, err := rs.ReadAt(p[:50], 10); err != nil {
+				t.Fatalf("ReadAt of the unmodified member = %v", err)
+			}
+			_, err = rs.ReadAt(p, 20)
+			var modErr *ObjectModifiedError
+			if !errors.Is(err, ErrObjectModified) || !errors.As(err, &modErr) {
+				t.Fatalf("ReadAt after the overwrite = %v, want an *ObjectModifiedError", err)
+			}
+			if modErr.Bucket != testBucket || modErr.Key != keys[1] || modErr.Offset != 0 {
+				t.Errorf("error for %s/%s at %d, want %s/%s at 0", modErr.Bucket, modErr.Key, modErr.Offset, testBucket, keys[1])
+			}
+			mode.check(t, modErr, old)
+		})
+	}
+}
+
+func TestModifiedMemberWithoutConsistency(t *testing.T) {
+	f := newFakeS3()
+	keys, _ := fakeMembers(f, 100, 100)
+	rs, err := NewS3ReadSeeker(f, testBucket, keys, WithConsistency(ConsistencyNone))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	f.put(keys[1], bytes.Repeat([]byte{'x'}, 100))
+	p := make([]byte, 10)
+	if _, err := rs.ReadAt(p, 150); err != nil || !bytes.Equal(p, bytes.Repeat([]byte{'x'}, 10)) {
+		t.Fatalf("ReadAt = %q, %v, want the new content", p, err)
+	}
+	for _, in := range f.gets {
+		if in.IfMatch != nil || in.IfUnmodifiedSince != nil {
+			t.Errorf("GetObject with a precondition without consistency checks")
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package s3ReadSeeker
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func TestStoreIgnoringRange(t *testing.T) {
+	f := newFakeS3()
+	keys, all := fakeMembers(f, 100, 50)
+	f.ignoreRange = true
+	rs, err := NewS3ReadSeeker(f, testBucket, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	// reads of whole members are answered correctly
+	p := make([]byte, 50)
+	if n, err := rs.ReadAt(p, 100); err != nil && err != io.EOF || !bytes.Equal(p[:n], all[100:]) {
+		t.Fatalf("ReadAt of a whole member = %d, %v", n, err)
+	}
+	// the response to a range within a member starts at the wrong offset
+	for _, off := range []int64{0, 10, 120} {
+		p := make([]byte, 20)
+		n, err := rs.ReadAt(p, off)
+		if !errors.Is(err, ErrUnexpectedRange) {
+			t.Errorf("ReadAt(20 bytes, %d) = %d, %v, want ErrUnexpectedRange", off, n, err)
+		}
+	}
+	var rangeErr *UnexpectedRangeError
+	if _, err := rs.ReadAt(make([]byte, 20), 10); !errors.As(err, &rangeErr) || rangeErr.Range != "bytes=10-29" || rangeErr.ContentLength != 100 {
+		t.Errorf("ReadAt error = %#v, want an *UnexpectedRangeError for bytes=10-29 with length 100", err)
+	}
+}
+
+func TestStoreReturningShortBody(t *testing.T) {
+	f := newFakeS3()
+	keys, _ := fakeMembers(f, 100)
+	// the body ends early without an error, as if the store
+	// misreported its length
+	f.wrapBody = func(_ *s3.GetObjectInput, body io.Reader, n int64) io.Reader {
+		return io.LimitReader(body, n/2)
+	}
+	rs, err := NewS3ReadSeeker(f, testBucket, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	var sizeErr *SizeMismatchError
+	if n, err := rs.ReadAt(make([]byte, 40), 20); !errors.As(err, &sizeErr) {
+		t.Fatalf("ReadAt of a short body = %d, %v, want a *SizeMismatchError", n, err)
+	}
+}
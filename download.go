@@ -0,0 +1,99 @@
+package s3ReadSeeker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// downloadChunkSize is the size of the ranges DownloadTo fetches.
+const downloadChunkSize = 8 << 20
+
+// downloadChunk is a range fetched by DownloadTo.
+type downloadChunk struct {
+	buf []byte
+	err error
+}
+
+// DownloadTo writes the whole stream to w, fetching it in ranges of 8 MiB
+// with up to concurrency ranges in flight and writing them in order. At
+// most concurrency ranges are buffered at a time. It returns the number of
+// bytes written and the first error. It does not use or move the current
+// offset; use WriteTo to stream from it with one request per member.
+func (s *S3ReadSeeker) DownloadTo(ctx context.Context, w io.Writer, concurrency int) (n int64, err error) {
+	if concurrency < 1 {
+		return 0, fmt.Errorf("invalid concurrency: %d", concurrency)
+	}
+	if s.closed.Load() {
+		return 0, ErrClosed
+	}
+	ctx, stop := s.bind(ctx)
+	defer stop()
+	if err := s.resolveAll(ctx); err != nil {
+		return 0, err
+	}
+	_, ends := s.resolvedMembers()
+	size := totalSize(ends)
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	// queue holds the pending chunks in stream order, and free the buffers
+	// of written ones, bounding the chunks in memory to concurrency
+	queue := make(chan chan downloadChunk, concurrency)
+	free := make(chan []byte, concurrency)
+	for i := 0; i < concurrency; i++ {
+		free <- nil
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(queue)
+		for off := int64(0); off < size; off += downloadChunkSize {
+			var buf []byte
+			select {
+			case buf = <-free:
+			case <-ctx.Done():
+				return
+			}
+			if buf == nil {
+				buf = make([]byte, downloadChunkSize)
+			}
+			buf = buf[:min(downloadChunkSize, size-off)]
+			result := make(chan downloadChunk, 1)
+			queue <- result
+			wg.Add(1)
+			go func(off int64) {
+				defer wg.Done()
+				m, err := s.readAt(ctx, buf, off)
+				if err == io.EOF {
+					// the members are shorter than their sizes said
+					err = io.ErrUnexpectedEOF
+				}
+				result <- downloadChunk{buf: buf[:m], err: err}
+			}(off)
+		}
+	}()
+	for result := range queue {
+		chunk := <-result
+		if chunk.err != nil {
+			return n, chunk.err
+		}
+		m, err := w.Write(chunk.buf)
+		n += int64(m)
+		s.stats.bytesReturned.Add(int64(m))
+		if err != nil {
+			return n, err
+		}
+		free <- chunk.buf[:cap(chunk.buf)]
+	}
+	if n < size {
+		// the producer stopped early because ctx was cancelled
+		return n, context.Cause(ctx)
+	}
+	return n, nil
+}
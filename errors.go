@@ -0,0 +1,312 @@
+package s3ReadSeeker
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Errors from S3 are translated at the boundary into the types below, each
+// of which wraps the original SDK error so errors.As on smithy.APIError and
+// the SDK's types keeps working:
+//
+//   - *KeyNotFoundError (ErrKeyNotFound) and *AccessDeniedError
+//     (ErrAccessDenied) from the constructors, which head members, and from
+//     reads of any member;
+//   - *SSECustomerKeyError (ErrSSECustomerKey and ErrAccessDenied) in
+//     place of *AccessDeniedError for members read with an SSE-C key;
+//   - *InvalidRangeError (ErrInvalidRange), wrapped in a
+//     *SizeMismatchError, from reads past the real end of a member;
+//   - *ObjectModifiedError (ErrObjectModified) from reads of a member that
+//     was overwritten after its size was resolved;
+//   - *ObjectArchivedError (ErrObjectArchived) from the constructors, or
+//     with WithAllowArchived from the first read of the member, for a
+//     member in an archive storage class that has not been restored;
+//   - *UnexpectedRangeError (ErrUnexpectedRange) from reads served by a
+//     store that does not honor the requested range;
+//   - ErrClosed from reads and seeks after Close.
+var (
+	// ErrClosed is returned by reads and seeks on an S3ReadSeeker after
+	// Close.
+	ErrClosed = errors.New("s3 read seeker is closed")
+	// ErrNegativeOffset is returned by ReadAt and Seek for offsets before
+	// the start of the stream.
+	ErrNegativeOffset = errors.New("negative offset")
+	// ErrOffsetOutOfRange is returned by LocateOffset for offsets at or
+	// past the end of the stream, and by Seek for offsets past it.
+	ErrOffsetOutOfRange = errors.New("offset out of range")
+	// ErrMemberNotFound is returned by OffsetOf for a key that is not
+	// among the members.
+	ErrMemberNotFound = errors.New("member not found")
+	// ErrNoObjects is returned by NewS3ReadSeekerFromPrefix with
+	// WithRequireNonEmpty when no object matches.
+	ErrNoObjects = errors.New("no objects found")
+	// ErrKeyNotFound is matched by errors.Is for a *KeyNotFoundError.
+	ErrKeyNotFound = errors.New("key not found")
+	// ErrAccessDenied is matched by errors.Is for an *AccessDeniedError
+	// and an *SSECustomerKeyError.
+	ErrAccessDenied = errors.New("access denied")
+	// ErrInvalidRange is matched by errors.Is for an *InvalidRangeError.
+	ErrInvalidRange = errors.New("invalid range")
+	// ErrUnexpectedRange is matched by errors.Is for an
+	// *UnexpectedRangeError.
+	ErrUnexpectedRange = errors.New("unexpected range in response")
+	// ErrObjectArchived is matched by errors.Is for an
+	// *ObjectArchivedError.
+	ErrObjectArchived = errors.New("object archived")
+	// ErrObjectModified is matched by errors.Is for an *ObjectModifiedError.
+	ErrObjectModified = errors.New("object modified")
+	// ErrSSECustomerKey is matched by errors.Is for an
+	// *SSECustomerKeyError.
+	ErrSSECustomerKey = errors.New("SSE-C key rejected")
+	// ErrChecksumMismatch is matched by errors.Is for a
+	// *ChecksumMismatchError.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	// ErrMemberUnavailable is matched by errors.Is for a
+	// *MemberUnavailableError.
+	ErrMemberUnavailable = errors.New("member unavailable")
+	// ErrUnsupportedSeek is returned by GzipReadSeeker.Seek for seeks the
+	// sequential decompressor cannot serve.
+	ErrUnsupportedSeek = errors.New("unsupported seek on compressed stream")
+)
+
+// SizeMismatchError is returned when S3 cannot serve a range that the
+// declared size of a member says should exist, e.g. because the size given
+// in an ObjectSpec is stale.
+type SizeMismatchError struct {
+	Bucket string
+	Key    string
+	Size   int64
+	Offset int64
+	Err    error
+}
+
+func (e *SizeMismatchError) Error() string {
+	return fmt.Sprintf("object %s/%s is shorter than its declared size %d at offset %d: %v", e.Bucket, e.Key, e.Size, e.Offset, e.Err)
+}
+
+func (e *SizeMismatchError) Unwrap() error {
+	return e.Err
+}
+
+// KeyNotFoundError is returned when a member, or the pinned version of it,
+// does not exist. It matches ErrKeyNotFound.
+type KeyNotFoundError struct {
+	Bucket    string
+	Key       string
+	VersionId string
+	Err       error
+}
+
+func (e *KeyNotFoundError) Error() string {
+	if e.VersionId != "" {
+		return fmt.Sprintf("version %s of object %s/%s not found: %v", e.VersionId, e.Bucket, e.Key, e.Err)
+	}
+	return fmt.Sprintf("object %s/%s not found: %v", e.Bucket, e.Key, e.Err)
+}
+
+func (e *KeyNotFoundError) Unwrap() error {
+	return e.Err
+}
+
+func (e *KeyNotFoundError) Is(target error) bool {
+	return target == ErrKeyNotFound
+}
+
+// AccessDeniedError is returned when S3 refuses a request for a member
+// with 403 Forbidden. It matches ErrAccessDenied.
+type AccessDeniedError struct {
+	Bucket string
+	Key    string
+	Err    error
+}
+
+func (e *AccessDeniedError) Error() string {
+	return fmt.Sprintf("access denied to object %s/%s: %v", e.Bucket, e.Key, e.Err)
+}
+
+func (e *AccessDeniedError) Unwrap() error {
+	return e.Err
+}
+
+func (e *AccessDeniedError) Is(target error) bool {
+	return target == ErrAccessDenied
+}
+
+// InvalidRangeError is returned when S3 rejects the Range of a GetObject
+// as unsatisfiable. Reads return it wrapped in a *SizeMismatchError, since
+// the range was computed from the size the member was believed to have. It
+// matches ErrInvalidRange.
+type InvalidRangeError struct {
+	Bucket string
+	Key    string
+	Range  string
+	Err    error
+}
+
+func (e *InvalidRangeError) Error() string {
+	return fmt.Sprintf("invalid range %s for object %s/%s: %v", e.Range, e.Bucket, e.Key, e.Err)
+}
+
+func (e *InvalidRangeError) Unwrap() error {
+	return e.Err
+}
+
+func (e *InvalidRangeError) Is(target error) bool {
+	return target == ErrInvalidRange
+}
+
+// UnexpectedRangeError is returned when the response to a ranged GetObject
+// starts elsewhere or is longer than the Range asked for, as when an
+// S3-compatible store ignores a Range header and returns the whole
+// object. ContentRange is empty if the response had none. It matches
+// ErrUnexpectedRange.
+type UnexpectedRangeError struct {
+	Bucket        string
+	Key           string
+	Range         string
+	ContentRange  string
+	ContentLength int64
+}
+
+func (e *UnexpectedRangeError) Error() string {
+	return fmt.Sprintf("object %s/%s: response to %s has Content-Range %q and length %d", e.Bucket, e.Key, e.Range, e.ContentRange, e.ContentLength)
+}
+
+func (e *UnexpectedRangeError) Is(target error) bool {
+	return target == ErrUnexpectedRange
+}
+
+// ObjectArchivedError is returned for a member in the Glacier Flexible
+// Retrieval or Deep Archive storage class, or an Intelligent-Tiering
+// archive access tier, that has not been restored and so cannot be read.
+// StorageClass names the class or tier. RestoreInProgress reports whether
+// a restore has been requested; it is only known when the error comes
+// from a HeadObject, in which case Err is nil. It matches
+// ErrObjectArchived.
+type ObjectArchivedError struct {
+	Bucket            string
+	Key               string
+	StorageClass      string
+	RestoreInProgress bool
+	Err               error
+}
+
+func (e *ObjectArchivedError) Error() string {
+	msg := fmt.Sprintf("object %s/%s is archived in %s", e.Bucket, e.Key, e.StorageClass)
+	if e.RestoreInProgress {
+		msg += " with a restore in progress"
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ObjectArchivedError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ObjectArchivedError) Is(target error) bool {
+	return target == ErrObjectArchived
+}
+
+// ObjectModifiedError is returned when a member no longer matches the
+// ETag, or with ConsistencyLastModified the last modification time, it had
+// when its size was resolved, i.e. it was overwritten while being read.
+// Only the field of the check that failed is set. It matches
+// ErrObjectModified.
+type ObjectModifiedError struct {
+	Bucket       string
+	Key          string
+	ETag         string
+	LastModified time.Time
+	Offset       int64
+	Err          error
+}
+
+func (e *ObjectModifiedError) Error() string {
+	if e.ETag == "" {
+		return fmt.Sprintf("object %s/%s was modified after %s, at offset %d: %v", e.Bucket, e.Key, e.LastModified.Format(time.RFC3339), e.Offset, e.Err)
+	}
+	return fmt.Sprintf("object %s/%s was modified since ETag %s was read, at offset %d: %v", e.Bucket, e.Key, e.ETag, e.Offset, e.Err)
+}
+
+func (e *ObjectModifiedError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ObjectModifiedError) Is(target error) bool {
+	return target == ErrObjectModified
+}
+
+// SSECustomerKeyError is returned when S3 denies a request that carried an
+// SSE-C key, which for an object that is otherwise readable means the key
+// is not the one it was encrypted with. As the denial may also come from
+// the caller's permissions, it matches both ErrSSECustomerKey and
+// ErrAccessDenied.
+type SSECustomerKeyError struct {
+	Bucket string
+	Key    string
+	Err    error
+}
+
+func (e *SSECustomerKeyError) Error() string {
+	return fmt.Sprintf("SSE-C key rejected for object %s/%s: %v", e.Bucket, e.Key, e.Err)
+}
+
+func (e *SSECustomerKeyError) Unwrap() error {
+	return e.Err
+}
+
+func (e *SSECustomerKeyError) Is(target error) bool {
+	return target == ErrSSECustomerKey || target == ErrAccessDenied
+}
+
+// ChecksumMismatchError is returned with WithChecksumValidation when the
+// data S3 returned for a member does not match the checksum stored with
+// it. Range is the Range header of the GetObject, empty for the whole
+// object. It matches ErrChecksumMismatch.
+type ChecksumMismatchError struct {
+	Bucket string
+	Key    string
+	Range  string
+	Err    error
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	if e.Range != "" {
+		return fmt.Sprintf("checksum mismatch for object %s/%s %s: %v", e.Bucket, e.Key, e.Range, e.Err)
+	}
+	return fmt.Sprintf("checksum mismatch for object %s/%s: %v", e.Bucket, e.Key, e.Err)
+}
+
+func (e *ChecksumMismatchError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ChecksumMismatchError) Is(target error) bool {
+	return target == ErrChecksumMismatch
+}
+
+// MemberUnavailableError is returned without a request for a member whose
+// circuit breaker is open, see WithCircuitBreaker. Err is the last error
+// that counted towards opening it. It matches ErrMemberUnavailable.
+type MemberUnavailableError struct {
+	Bucket  string
+	Key     string
+	RetryAt time.Time
+	Err     error
+}
+
+func (e *MemberUnavailableError) Error() string {
+	return fmt.Sprintf("object %s/%s unavailable until %s: %v", e.Bucket, e.Key, e.RetryAt.Format(time.RFC3339), e.Err)
+}
+
+func (e *MemberUnavailableError) Unwrap() error {
+	return e.Err
+}
+
+func (e *MemberUnavailableError) Is(target error) bool {
+	return target == ErrMemberUnavailable
+}
@@ -0,0 +1,41 @@
+package s3ReadSeeker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+func TestErrorNamesFailingMember(t *testing.T) {
+	f := newFakeS3()
+	keys, _ := fakeMembers(f, 100, 100, 100)
+	f.onGet = func(_ context.Context, in *s3.GetObjectInput) error {
+		if aws.ToString(in.Key) == keys[1] {
+			return &smithy.GenericAPIError{Code: "InternalError", Message: "we encountered an internal error"}
+		}
+		return nil
+	}
+	rs, err := NewS3ReadSeeker(f, testBucket, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	_, err = rs.ReadAt(make([]byte, 150), 80)
+	if err == nil {
+		t.Fatal("ReadAt of a failing member succeeded")
+	}
+	for _, want := range []string{testBucket + "/" + keys[1], "bytes=0-99", "offset 100", "InternalError"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) || apiErr.ErrorCode() != "InternalError" {
+		t.Errorf("error %v does not wrap the InternalError", err)
+	}
+}
@@ -0,0 +1,99 @@
+package s3ReadSeeker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type flightKey struct {
+	bucket    string
+	key       string
+	versionId string
+	// off is the offset in the object, not in the member
+	off    int64
+	length int64
+}
+
+// flight is a fetch shared by concurrent reads of the same range.
+type flight struct {
+	done chan struct{}
+	data []byte
+	err  error
+	// waiters counts the reads still waiting for the fetch, which is
+	// canceled once none are left. It is guarded by the group's mu.
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// flightGroup tracks the fetches in progress, see WithDeduplication. It
+// is safe for concurrent use.
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[flightKey]*flight
+}
+
+func newFlightGroup() *flightGroup {
+	return &flightGroup{flights: make(map[flightKey]*flight)}
+}
+
+// fetchShared is like fetchRange, but joins a fetch of the same range
+// already in progress instead of issuing another GetObject. The fetch runs
+// detached from any single caller's context and is only canceled once all
+// callers waiting for it have given up. The breaker is consulted and
+// updated once per fetch, not once per caller.
+func (o *Object) fetchShared(ctx context.Context, p []byte, off int64) (n int, err error) {
+	g := o.flights
+	key := flightKey{bucket: o.bucketName, key: o.key, versionId: o.versionId, off: o.start + off, length: int64(len(p))}
+	g.mu.Lock()
+	f, ok := g.flights[key]
+	if ok {
+		f.waiters++
+		o.stats.dedupedRequests.Add(1)
+	} else {
+		if err := o.allow(); err != nil {
+			g.mu.Unlock()
+			return 0, err
+		}
+		fetchCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		f = &flight{done: make(chan struct{}), waiters: 1, cancel: cancel}
+		g.flights[key] = f
+		go func() {
+			buf := make([]byte, len(p))
+			n, err := o.fetchRange(fetchCtx, buf, off)
+			// a fetch canceled by its callers giving up is not recorded
+			o.record(fetchCtx, err)
+			g.remove(key, f)
+			cancel()
+			f.data, f.err = buf[:n], err
+			close(f.done)
+		}()
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return copy(p, f.data), f.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		f.waiters--
+		if f.waiters == 0 {
+			f.cancel()
+			// later reads must not join the canceled fetch
+			if g.flights[key] == f {
+				delete(g.flights, key)
+			}
+		}
+		g.mu.Unlock()
+		return 0, fmt.Errorf("get object %s bytes=%d-%d: %w", o.path(), off, off+int64(len(p))-1, ctx.Err())
+	}
+}
+
+// remove forgets f once its fetch is done.
+func (g *flightGroup) remove(key flightKey, f *flight) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.flights[key] == f {
+		delete(g.flights, key)
+	}
+}
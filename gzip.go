@@ -0,0 +1,102 @@
+package s3ReadSeeker
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// GzipReadSeeker reads the decompressed content of a gzip-compressed
+// S3ReadSeeker. Concatenated gzip members, such as a sequence of
+// separately compressed objects, are decompressed as one stream.
+//
+// Decompression is sequential: Seek supports moving forward, which
+// decompresses and discards the skipped bytes, and rewinding to offset
+// 0, but fails with ErrUnsupportedSeek for any other backward seek and
+// for io.SeekEnd. Offsets refer to the decompressed stream, whereas Size
+// of the underlying S3ReadSeeker still reports the compressed length.
+type GzipReadSeeker struct {
+	rs *S3ReadSeeker
+	// br buffers the reads of zr from rs, which would otherwise read the
+	// compressed stream a byte at a time
+	br    *bufio.Reader
+	zr    *gzip.Reader
+	start int64
+	off   int64
+}
+
+// gzipBufferSize is the size of the compressed chunks GzipReadSeeker reads.
+const gzipBufferSize = 256 << 10
+
+var _ io.ReadSeekCloser = (*GzipReadSeeker)(nil)
+
+// NewGzipReadSeeker returns a GzipReadSeeker decompressing rs from its
+// current offset. It reads the first gzip header, and fails if there is
+// none. Closing the GzipReadSeeker closes rs.
+func NewGzipReadSeeker(rs *S3ReadSeeker) (*GzipReadSeeker, error) {
+	start, err := rs.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReaderSize(rs, gzipBufferSize)
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, err
+	}
+	return &GzipReadSeeker{rs: rs, br: br, zr: zr, start: start}, nil
+}
+
+// Read reads decompressed bytes into p.
+func (g *GzipReadSeeker) Read(p []byte) (int, error) {
+	n, err := g.zr.Read(p)
+	g.off += int64(n)
+	return n, err
+}
+
+// Seek sets the offset in the decompressed stream for the next Read.
+func (g *GzipReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	var newOffset int64
+	switch whence {
+	case io.SeekStart:
+		newOffset = offset
+	case io.SeekCurrent:
+		newOffset = g.off + offset
+	case io.SeekEnd:
+		return 0, ErrUnsupportedSeek
+	default:
+		return 0, fmt.Errorf("invalid whence: %d", whence)
+	}
+	switch {
+	case newOffset < 0:
+		return 0, fmt.Errorf("%w: %d", ErrNegativeOffset, newOffset)
+	case newOffset == 0 && g.off > 0:
+		if err := g.rewind(); err != nil {
+			return 0, err
+		}
+	case newOffset < g.off:
+		return 0, fmt.Errorf("%w: backward to %d from %d", ErrUnsupportedSeek, newOffset, g.off)
+	}
+	_, err := io.CopyN(io.Discard, g, newOffset-g.off)
+	return g.off, err
+}
+
+// rewind restarts decompression from the start of the stream. rs has
+// been read ahead of what zr consumed, so the buffered bytes are dropped
+// and rs is moved back to start.
+func (g *GzipReadSeeker) rewind() error {
+	if _, err := g.rs.Seek(g.start, io.SeekStart); err != nil {
+		return err
+	}
+	g.br.Reset(g.rs)
+	if err := g.zr.Reset(g.br); err != nil {
+		return err
+	}
+	g.off = 0
+	return nil
+}
+
+// Close closes the underlying S3ReadSeeker.
+func (g *GzipReadSeeker) Close() error {
+	return g.rs.Close()
+}
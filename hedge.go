@@ -0,0 +1,69 @@
+package s3ReadSeeker
+
+import (
+	"context"
+	"time"
+)
+
+// readRangeHedged is like readRange, but if the request has not completed
+// within the delay of WithHedging it issues a second identical one and
+// returns whichever succeeds first, canceling the other.
+func (o *Object) readRangeHedged(ctx context.Context, p []byte, off int64, byteRange string) (n int, err error) {
+	if o.opts.hedgeDelay <= 0 {
+		return o.readRange(ctx, p, off, byteRange)
+	}
+	type result struct {
+		n     int
+		err   error
+		hedge bool
+	}
+	results := make(chan result, 2)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	primaryCtx, cancelPrimary := context.WithCancel(ctx)
+	defer cancelPrimary()
+	go func() {
+		n, err := o.readRange(primaryCtx, p, off, byteRange)
+		results <- result{n: n, err: err}
+	}()
+
+	timer := time.NewTimer(o.opts.hedgeDelay)
+	defer timer.Stop()
+	select {
+	case r := <-results:
+		return r.n, r.err
+	case <-timer.C:
+	case <-ctx.Done():
+		r := <-results
+		return r.n, r.err
+	}
+
+	// the hedge reads into its own buffer, as the primary request may
+	// still be writing to p
+	o.stats.hedgedRequests.Add(1)
+	buf := make([]byte, len(p))
+	go func() {
+		n, err := o.readRange(ctx, buf, off, byteRange)
+		results <- result{n: n, err: err, hedge: true}
+	}()
+	first := <-results
+	if first.err == nil && !first.hedge {
+		// the deferred cancel stops the hedge
+		return first.n, nil
+	}
+	if first.err == nil {
+		cancelPrimary()
+		<-results
+		o.stats.hedgeWins.Add(1)
+		return copy(p, buf[:first.n]), nil
+	}
+	second := <-results
+	switch {
+	case second.err == nil && second.hedge:
+		o.stats.hedgeWins.Add(1)
+		return copy(p, buf[:second.n]), nil
+	case !second.hedge:
+		return second.n, second.err
+	}
+	return first.n, first.err
+}
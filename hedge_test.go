@@ -0,0 +1,73 @@
+package s3ReadSeeker
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func TestHedgeWinsAgainstSlowResponse(t *testing.T) {
+	f := newFakeS3()
+	keys, all := fakeMembers(f, 1000)
+	var mu sync.Mutex
+	calls := 0
+	slowCanceled := make(chan struct{})
+	f.onGet = func(ctx context.Context, _ *s3.GetObjectInput) error {
+		mu.Lock()
+		calls++
+		first := calls == 1
+		mu.Unlock()
+		if !first {
+			return nil
+		}
+		// the first response stalls until the hedge cancels it
+		err := sleep(ctx, time.Minute)
+		close(slowCanceled)
+		return err
+	}
+	rs, err := NewS3ReadSeekerFromSizes(f, testBucket, keys, []int64{1000}, WithHedging(20*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	p := make([]byte, 100)
+	start := time.Now()
+	n, err := rs.ReadAt(p, 300)
+	if err != nil || n != len(p) || !bytes.Equal(p, all[300:400]) {
+		t.Fatalf("ReadAt = %d, %v, want the %d bytes at 300", n, err, len(p))
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("ReadAt took %s waiting for the slow response", elapsed)
+	}
+	select {
+	case <-slowCanceled:
+	case <-time.After(10 * time.Second):
+		t.Error("the slow request was not canceled")
+	}
+	stats := rs.Stats()
+	if stats.GetRequests != 2 || stats.HedgedRequests != 1 || stats.HedgeWins != 1 {
+		t.Errorf("GetRequests = %d, HedgedRequests = %d, HedgeWins = %d, want 2, 1, 1",
+			stats.GetRequests, stats.HedgedRequests, stats.HedgeWins)
+	}
+}
+
+func TestHedgeNotIssuedForFastResponse(t *testing.T) {
+	f := newFakeS3()
+	keys, all := fakeMembers(f, 1000)
+	rs, err := NewS3ReadSeekerFromSizes(f, testBucket, keys, []int64{1000}, WithHedging(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	p := make([]byte, 100)
+	if n, err := rs.ReadAt(p, 0); err != nil || !bytes.Equal(p[:n], all[:100]) {
+		t.Fatalf("ReadAt = %d, %v", n, err)
+	}
+	if stats := rs.Stats(); stats.GetRequests != 1 || stats.HedgedRequests != 0 {
+		t.Errorf("GetRequests = %d, HedgedRequests = %d, want 1, 0", stats.GetRequests, stats.HedgedRequests)
+	}
+}
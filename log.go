@@ -0,0 +1,90 @@
+package s3ReadSeeker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// reportRequest reports an S3 request for o that read n body bytes to the
+// Metrics of WithMetrics and the logger of WithLogger, if given. output is
+// the operation's output, which may be nil.
+func (o *Object) reportRequest(ctx context.Context, op, byteRange string, start time.Time, output interface{}, n int64, err error) {
+	if o.opts.logger == nil && o.opts.metrics == nil {
+		return
+	}
+	duration := time.Since(start)
+	if o.opts.metrics != nil {
+		o.opts.metrics.ObserveRequest(op, n, duration, err)
+	}
+	if o.opts.logger == nil {
+		return
+	}
+	var metadata middleware.Metadata
+	switch out := output.(type) {
+	case *s3.GetObjectOutput:
+		if out != nil {
+			metadata = out.ResultMetadata
+		}
+	case *s3.HeadObjectOutput:
+		if out != nil {
+			metadata = out.ResultMetadata
+		}
+	}
+	o.opts.logger.DebugContext(ctx, op,
+		slog.String("bucket", o.bucketName),
+		slog.String("key", o.key),
+		slog.String("range", byteRange),
+		slog.Int64("bytes", n),
+		slog.Duration("duration", duration),
+		slog.Int("status", httpStatus(metadata, err)),
+		slog.Any("error", err),
+	)
+}
+
+// requestIDs formats the S3 request ID and extended request ID of a
+// response for an error message, or returns "" if metadata has neither.
+func requestIDs(metadata middleware.Metadata) string {
+	requestID, _ := awsmiddleware.GetRequestIDMetadata(metadata)
+	hostID, _ := s3.GetHostIDMetadata(metadata)
+	if requestID == "" && hostID == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (request id %s, host id %s)", requestID, hostID)
+}
+
+// bodyError wraps an error reading the body of the GetObject of byteRange
+// with the key, range and the IDs of the response, which the SDK only adds
+// to errors of the request itself.
+func (o *Object) bodyError(err error, byteRange string, metadata middleware.Metadata) error {
+	return fmt.Errorf("get object %s %s%s: %w", o.path(), byteRange, requestIDs(metadata), err)
+}
+
+// httpStatus returns the HTTP status code of a response, or 0 if there was
+// no response.
+func httpStatus(metadata middleware.Metadata, err error) int {
+	if status := errorStatus(err); status != 0 {
+		return status
+	}
+	if resp, ok := awsmiddleware.GetRawResponse(metadata).(*smithyhttp.Response); ok {
+		return resp.StatusCode
+	}
+	return 0
+}
+
+// errorStatus returns the HTTP status code of the response err was built
+// from, or 0 if there was none.
+func errorStatus(err error) int {
+	var respErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &respErr) {
+		return respErr.HTTPStatusCode()
+	}
+	return 0
+}
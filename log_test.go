@@ -0,0 +1,80 @@
+package s3ReadSeeker
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// captureHandler is a slog.Handler that keeps the records it handles.
+type captureHandler struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *captureHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *captureHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, r)
+	return nil
+}
+
+func (h *captureHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *captureHandler) WithGroup(string) slog.Handler      { return h }
+
+// attrs returns the attributes of the records with message msg, by key.
+func (h *captureHandler) attrs(msg string) []map[string]slog.Value {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var all []map[string]slog.Value
+	for _, r := range h.records {
+		if r.Message != msg {
+			continue
+		}
+		attrs := make(map[string]slog.Value)
+		r.Attrs(func(a slog.Attr) bool {
+			attrs[a.Key] = a.Value
+			return true
+		})
+		all = append(all, attrs)
+	}
+	return all
+}
+
+func TestLoggerOneEntryPerGetObject(t *testing.T) {
+	f := newFakeS3()
+	keys, _ := fakeMembers(f, 100, 100, 100)
+	h := &captureHandler{}
+	rs, err := NewS3ReadSeeker(f, testBucket, keys, WithLogger(slog.New(h)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	if got := len(h.attrs("HeadObject")); got != len(keys) {
+		t.Errorf("HeadObject entries = %d, want %d", got, len(keys))
+	}
+	if _, err := rs.ReadAt(make([]byte, 200), 50); err != nil {
+		t.Fatal(err)
+	}
+	entries := h.attrs("GetObject")
+	if len(entries) != len(keys) {
+		t.Fatalf("GetObject entries = %d, want %d", len(entries), len(keys))
+	}
+	var ranges []string
+	for _, attrs := range entries {
+		if attrs["bucket"].String() != testBucket || attrs["error"].Any() != nil {
+			t.Errorf("entry for bucket %s with error %v", attrs["bucket"], attrs["error"])
+		}
+		ranges = append(ranges, attrs["key"].String()+" "+attrs["range"].String())
+	}
+	// the members are read concurrently, so in any order
+	slices.Sort(ranges)
+	want := []string{keys[0] + " bytes=50-99", keys[1] + " bytes=0-99", keys[2] + " bytes=0-49"}
+	if !slices.Equal(ranges, want) {
+		t.Errorf("GetObject entries for %q, want %q", ranges, want)
+	}
+}
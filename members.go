@@ -0,0 +1,172 @@
+package s3ReadSeeker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Member describes a member of an S3ReadSeeker and where it lies in the
+// concatenated stream.
+type Member struct {
+	Bucket    string
+	Key       string
+	VersionId string
+	// Size is the number of bytes of the member in the stream and Offset
+	// where they start. Both are UnknownSize for members whose sizes have
+	// not been resolved yet with WithLazySizes.
+	Size   int64
+	Offset int64
+	// Start is the number of leading bytes of the object trimmed from
+	// the stream, see ObjectSpec.
+	Start int64
+	// ETag and LastModified are those the object had when its size was
+	// resolved. They are empty if unknown, e.g. for sizes given to the
+	// constructor.
+	ETag         string
+	LastModified time.Time
+}
+
+// Members returns the members in stream order. It issues no requests, and
+// the slice is a copy that later calls to AppendKeys or Refresh do not
+// change.
+func (s *S3ReadSeeker) Members() []Member {
+	objs, ends := s.members()
+	members := make([]Member, len(objs))
+	for i := range objs {
+		members[i] = member(objs, ends, i)
+	}
+	return members
+}
+
+// member describes the member at index i of members, given the cumulative
+// end offsets of the resolved ones.
+func member(members []*Object, ends []int64, i int) Member {
+	obj := members[i]
+	m := Member{
+		Bucket:    obj.bucketName,
+		Key:       obj.key,
+		VersionId: obj.versionId,
+		Start:     obj.start,
+		Size:      UnknownSize,
+		Offset:    UnknownSize,
+	}
+	if i < len(ends) {
+		m.Size = obj.size
+		m.Offset = ends[i] - obj.size
+		m.ETag = obj.etag
+		m.LastModified = obj.lastModified
+	}
+	return m
+}
+
+// NumMembers returns the number of members.
+func (s *S3ReadSeeker) NumMembers() int {
+	members, _ := s.members()
+	return len(members)
+}
+
+// LocateOffset returns the index in Members of the member holding the byte
+// at off and the offset of that byte within the member. Empty members
+// never hold a byte. With WithLazySizes it resolves the sizes of the
+// members up to off with the stored context.
+func (s *S3ReadSeeker) LocateOffset(off int64) (index int, local int64, err error) {
+	if off < 0 {
+		return 0, 0, fmt.Errorf("%w: %d", ErrNegativeOffset, off)
+	}
+	if err := s.resolveUntil(s.context(), off+1); err != nil {
+		return 0, 0, err
+	}
+	members, ends := s.resolvedMembers()
+	i := sort.Search(len(ends), func(i int) bool { return ends[i] > off })
+	if i == len(ends) {
+		return 0, 0, fmt.Errorf("%w: %d beyond %d bytes", ErrOffsetOutOfRange, off, totalSize(ends))
+	}
+	return i, off - (ends[i] - members[i].size), nil
+}
+
+// Locate is like LocateOffset but describes the member holding the byte
+// at off instead of returning its index.
+func (s *S3ReadSeeker) Locate(off int64) (m Member, local int64, err error) {
+	i, local, err := s.LocateOffset(off)
+	if err != nil {
+		return Member{}, 0, err
+	}
+	members, ends := s.members()
+	return member(members, ends, i), local, nil
+}
+
+// OffsetOf returns where the first member with key starts in the stream
+// and its size. If no member has key, the error matches ErrMemberNotFound.
+// With WithLazySizes it resolves the sizes of the members up to that one
+// with the stored context.
+func (s *S3ReadSeeker) OffsetOf(key string) (start, length int64, err error) {
+	s.sizeMu.Lock()
+	i, ok := s.keyIndex[key]
+	s.sizeMu.Unlock()
+	if !ok {
+		return 0, 0, fmt.Errorf("%w: %s", ErrMemberNotFound, key)
+	}
+	members, ends := s.members()
+	for len(ends) <= i {
+		if err := s.resolveUntil(s.context(), totalSize(ends)+1); err != nil {
+			return 0, 0, err
+		}
+		members, ends = s.members()
+	}
+	return ends[i] - members[i].size, members[i].size, nil
+}
+
+// AppendKey appends the object key in the seeker's bucket to the end of
+// the stream, see AppendKeys.
+func (s *S3ReadSeeker) AppendKey(key string) error {
+	return s.AppendKeys([]string{key})
+}
+
+// AppendKeys appends the objects keys in the seeker's bucket to the end of
+// the stream, in the order given, with one HeadObject per new key issued
+// with the stored context, or none with WithLazySizes. The current offset
+// and reads in progress are not affected, while Size and Seek see the new
+// members once it returns. If a HeadObject fails, no key is appended.
+func (s *S3ReadSeeker) AppendKeys(keys []string) error {
+	return s.AppendKeysContext(s.context(), keys)
+}
+
+// AppendKeysContext is like AppendKeys but issues the HeadObject calls
+// with ctx.
+func (s *S3ReadSeeker) AppendKeysContext(ctx context.Context, keys []string) error {
+	if s.closed.Load() {
+		return ErrClosed
+	}
+	if s.bucketName == "" {
+		return errors.New("no bucket to append objects from")
+	}
+	objs := make([]*Object, len(keys))
+	for i, key := range keys {
+		objs[i] = s.newObject(ObjectSpec{Key: key, Size: UnknownSize})
+	}
+	if !s.opts.lazySizes {
+		if err := headObjects(ctx, objs, s.opts.headLimit()); err != nil {
+			return err
+		}
+	}
+	s.resolveMu.Lock()
+	defer s.resolveMu.Unlock()
+	s.sizeMu.Lock()
+	defer s.sizeMu.Unlock()
+	// ends only covers a leading run of resolved members
+	complete := len(s.ends) == len(s.objectMembers)
+	first := len(s.objectMembers)
+	s.objectMembers = append(s.objectMembers, objs...)
+	s.indexKeys(first)
+	if complete {
+		n := 0
+		for n < len(objs) && objs[n].resolved {
+			n++
+		}
+		s.appendEnds(objs[:n])
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package s3ReadSeeker
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLocateWithEmptyMembers(t *testing.T) {
+	f := newFakeS3()
+	// members 0, 2, 3 and 6 are empty
+	keys, all := fakeMembers(f, 0, 10, 0, 0, 5, 1, 0)
+	rs, err := NewS3ReadSeeker(f, testBucket, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	for _, tc := range []struct {
+		off   int64
+		index int
+		local int64
+	}{
+		{0, 1, 0},
+		{9, 1, 9},
+		// the boundary after member 1 maps to the start of member 4
+		{10, 4, 0},
+		{14, 4, 4},
+		{15, 5, 0},
+	} {
+		index, local, err := rs.LocateOffset(tc.off)
+		if err != nil || index != tc.index || local != tc.local {
+			t.Errorf("LocateOffset(%d) = %d, %d, %v, want %d, %d", tc.off, index, local, err, tc.index, tc.local)
+			continue
+		}
+		m, local, err := rs.Locate(tc.off)
+		if err != nil || m.Key != keys[tc.index] || local != tc.local || m.Offset+local != tc.off || m.Size == 0 {
+			t.Errorf("Locate(%d) = %+v, %d, %v, want member %s at %d", tc.off, m, local, err, keys[tc.index], tc.local)
+		}
+	}
+	for _, off := range []int64{int64(len(all)), int64(len(all)) + 1} {
+		if _, _, err := rs.Locate(off); !errors.Is(err, ErrOffsetOutOfRange) {
+			t.Errorf("Locate(%d) error = %v, want ErrOffsetOutOfRange", off, err)
+		}
+	}
+	if _, _, err := rs.Locate(-1); !errors.Is(err, ErrNegativeOffset) {
+		t.Errorf("Locate(-1) error = %v, want ErrNegativeOffset", err)
+	}
+	if got := f.getCount(); got != 0 {
+		t.Errorf("Locate issued %d GetObjects", got)
+	}
+}
@@ -0,0 +1,14 @@
+package s3ReadSeeker
+
+import "time"
+
+// Metrics receives a report of every S3 request a seeker issues, e.g. to
+// export them to Prometheus, see WithMetrics. It must be safe for
+// concurrent use.
+type Metrics interface {
+	// ObserveRequest is called when a GetObject or HeadObject call is
+	// done, with the operation name, the number of body bytes read, the
+	// time taken and the error, if any. A GetObject kept open by
+	// WithStreaming is reported once its response arrives, with 0 bytes.
+	ObserveRequest(op string, bytes int64, duration time.Duration, err error)
+}
@@ -0,0 +1,577 @@
+package s3ReadSeeker
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultHeadConcurrency is the default maximum number of HeadObject calls
+// issued concurrently while resolving member sizes.
+const DefaultHeadConcurrency = 16
+
+// Option configures an S3ReadSeeker. Options are applied by the
+// constructors, which return the first error an option reports.
+type Option func(*options) error
+
+type options struct {
+	lazySizes            bool
+	headConcurrency      int
+	requestTimeout       time.Duration
+	logger               *slog.Logger
+	metrics              Metrics
+	readAheadSize        int
+	prefetch             bool
+	prefetchDepth        int
+	streaming            bool
+	retryConfig          RetryConfig
+	cacheSize            int64
+	cacheBlockSize       int64
+	minFetchSize         int64
+	smallObjectThreshold int64
+	naturalKeyOrder      bool
+	readConcurrency      int
+	maxInFlight          int64
+	bandwidthLimit       int
+	hedgeDelay           time.Duration
+	breakerThreshold     int
+	breakerCooldown      time.Duration
+	consistency          Consistency
+	allowArchived        bool
+	checksumValidation   bool
+	dedupe               bool
+	sseCustomerKey       *sseCustomerKey
+	tracerProvider       trace.TracerProvider
+	requesterPays        bool
+	s3Options            []func(*s3.Options)
+
+	// used by NewS3ReadSeekerFromPrefix
+	listFilter      func(key string) bool
+	startAfter      string
+	requireNonEmpty bool
+}
+
+func defaultOptions() options {
+	return options{consistency: ConsistencyETag}
+}
+
+// WithLazySizes defers the HeadObject calls that resolve member sizes until
+// they are needed, so the constructor issues no requests. A ReadAt or Seek
+// only resolves the members up to the end of the requested range or the
+// new offset, while Seek(0, io.SeekEnd) and Size resolve all of them.
+// Errors from a deferred HeadObject are returned by the Read or Seek that
+// triggered it.
+func WithLazySizes() Option {
+	return func(o *options) error {
+		o.lazySizes = true
+		return nil
+	}
+}
+
+// WithHeadConcurrency sets the maximum number of HeadObject calls issued
+// concurrently while resolving member sizes. It defaults to the value of
+// WithMaxConcurrency if that is given, else to DefaultHeadConcurrency.
+func WithHeadConcurrency(n int) Option {
+	return func(o *options) error {
+		if n < 1 {
+			return fmt.Errorf("invalid head concurrency: %d", n)
+		}
+		o.headConcurrency = n
+		return nil
+	}
+}
+
+// WithRequestTimeout bounds each individual S3 request, including reading
+// its response body, to d, independently of the deadline of the caller's
+// context. The error of a request that times out names the key, the range
+// and d, and matches context.DeadlineExceeded; with WithRetryConfig the
+// request is reissued.
+func WithRequestTimeout(d time.Duration) Option {
+	return func(o *options) error {
+		if d <= 0 {
+			return fmt.Errorf("invalid request timeout: %s", d)
+		}
+		o.requestTimeout = d
+		return nil
+	}
+}
+
+// WithLogger logs every S3 request at debug level to l, with the bucket,
+// key, byte range, bytes read, duration, HTTP status and error. Logging
+// never affects the outcome of a request.
+func WithLogger(l *slog.Logger) Option {
+	return func(o *options) error {
+		if l == nil {
+			return errors.New("nil logger")
+		}
+		o.logger = l
+		return nil
+	}
+}
+
+// WithMetrics reports every S3 request to m. Without it no metrics are
+// collected.
+func WithMetrics(m Metrics) Option {
+	return func(o *options) error {
+		if m == nil {
+			return errors.New("nil metrics")
+		}
+		o.metrics = m
+		return nil
+	}
+}
+
+// WithReadAheadSize makes Read fetch at least n bytes per GetObject and
+// serve subsequent Reads from memory until the buffered window is
+// exhausted or a Seek moves outside of it. ReadAt is not buffered.
+func WithReadAheadSize(n int) Option {
+	return func(o *options) error {
+		if n < 1 {
+			return fmt.Errorf("invalid read-ahead size: %d", n)
+		}
+		o.readAheadSize = n
+		return nil
+	}
+}
+
+// WithReadAhead is like WithReadAheadSize, but once Read consumes the
+// stream sequentially it also fetches the following window of n bytes in
+// the background, so that the next Read past the current window is served
+// from memory. The prefetch uses the stored context, see SetContext, and
+// is canceled by Close and by a Seek to elsewhere.
+func WithReadAhead(n int) Option {
+	return func(o *options) error {
+		if n < 1 {
+			return fmt.Errorf("invalid read-ahead size: %d", n)
+		}
+		o.readAheadSize = n
+		o.prefetch = true
+		o.prefetchDepth = max(o.prefetchDepth, 1)
+		return nil
+	}
+}
+
+// WithPrefetchDepth makes WithReadAhead keep up to n windows following the
+// current one in flight or in memory, rather than one. It has no effect
+// without WithReadAhead.
+func WithPrefetchDepth(n int) Option {
+	return func(o *options) error {
+		if n < 1 {
+			return fmt.Errorf("invalid prefetch depth: %d", n)
+		}
+		o.prefetchDepth = n
+		return nil
+	}
+}
+
+// WithStreaming makes Read keep a single GetObject body open from the
+// current offset to the end of the current member and serve subsequent
+// Reads from it, so that a sequential pass issues one request per member.
+// A Seek to a different offset or Close releases the body. Reads do not
+// span members, and WithRequestTimeout does not apply to them. The body
+// is opened with the context of the Read, the stored context for Read,
+// and reopened by a ReadContext with a different one. The body holds its
+// slot of WithMaxInFlightRequests until released. WithStreaming cannot be
+// combined with WithReadAheadSize or WithReadAhead.
+func WithStreaming() Option {
+	return func(o *options) error {
+		o.streaming = true
+		return nil
+	}
+}
+
+// WithMaxConcurrency lets a ReadAt that spans several members fetch up to
+// n of them concurrently. By default members are read one after another.
+// Unless WithHeadConcurrency is given, n also bounds the concurrent
+// HeadObject calls that resolve member sizes.
+func WithMaxConcurrency(n int) Option {
+	return func(o *options) error {
+		if n < 1 {
+			return fmt.Errorf("invalid max concurrency: %d", n)
+		}
+		o.readConcurrency = n
+		return nil
+	}
+}
+
+// WithMaxInFlightRequests limits the number of GetObject and HeadObject
+// requests a seeker has in flight at once to n, across all goroutines and
+// code paths using it, including prefetching. Requests over the limit
+// wait for a slot, or until their context is done. Stats.InFlightRequests
+// reports the current number.
+func WithMaxInFlightRequests(n int) Option {
+	return func(o *options) error {
+		if n < 1 {
+			return fmt.Errorf("invalid max in-flight requests: %d", n)
+		}
+		o.maxInFlight = int64(n)
+		return nil
+	}
+}
+
+// WithBandwidthLimit limits the rate at which a seeker reads GetObject
+// bodies to bytesPerSecond, combined across Read, ReadAt, WriteTo and
+// prefetching, with bursts of up to one second's worth. A read waiting
+// for the limiter returns early when its context is done.
+func WithBandwidthLimit(bytesPerSecond int) Option {
+	return func(o *options) error {
+		if bytesPerSecond < 1 {
+			return fmt.Errorf("invalid bandwidth limit: %d", bytesPerSecond)
+		}
+		o.bandwidthLimit = bytesPerSecond
+		return nil
+	}
+}
+
+// WithHedging reissues a ranged GetObject that has not completed after
+// delay as a second, identical request and uses whichever completes
+// first, canceling the other, to cut tail latency. Both requests count
+// against WithMaxInFlightRequests. Stats report the hedges issued and
+// how many of them won.
+func WithHedging(delay time.Duration) Option {
+	return func(o *options) error {
+		if delay <= 0 {
+			return fmt.Errorf("invalid hedging delay: %s", delay)
+		}
+		o.hedgeDelay = delay
+		return nil
+	}
+}
+
+// WithCircuitBreaker makes reads of a member fail fast with a
+// *MemberUnavailableError once threshold consecutive requests for it have
+// failed, after retries. After cooldown a single probe request is let
+// through: if it succeeds the member is available again, otherwise it
+// stays unavailable for another cooldown. Health reports the state.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(o *options) error {
+		if threshold < 1 {
+			return fmt.Errorf("invalid circuit breaker threshold: %d", threshold)
+		}
+		if cooldown <= 0 {
+			return fmt.Errorf("invalid circuit breaker cooldown: %s", cooldown)
+		}
+		o.breakerThreshold = threshold
+		o.breakerCooldown = cooldown
+		return nil
+	}
+}
+
+// RetryConfig configures how GetObject and HeadObject calls that fail with
+// a transient error, such as a 5xx response, SlowDown, RequestTimeout or a
+// network error, are retried. The zero value disables retries.
+type RetryConfig struct {
+	// MaxAttempts is the total number of attempts per request. Values
+	// below 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. It doubles with each
+	// further attempt and is randomized with full jitter.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts if it is positive.
+	MaxDelay time.Duration
+}
+
+// WithRetryConfig sets the retry policy for transient S3 errors.
+func WithRetryConfig(c RetryConfig) Option {
+	return func(o *options) error {
+		if c.MaxAttempts < 0 {
+			return fmt.Errorf("invalid max attempts: %d", c.MaxAttempts)
+		}
+		if c.BaseDelay < 0 || c.MaxDelay < 0 {
+			return fmt.Errorf("invalid retry delays: %s, %s", c.BaseDelay, c.MaxDelay)
+		}
+		o.retryConfig = c
+		return nil
+	}
+}
+
+// WithRetry retries transient failures up to maxAttempts attempts in
+// total, starting with baseDelay between attempts. It is shorthand for
+// WithRetryConfig.
+func WithRetry(maxAttempts int, baseDelay time.Duration) Option {
+	if maxAttempts < 1 {
+		return func(o *options) error {
+			return fmt.Errorf("invalid max attempts: %d", maxAttempts)
+		}
+	}
+	return WithRetryConfig(RetryConfig{MaxAttempts: maxAttempts, BaseDelay: baseDelay})
+}
+
+// WithRangeCache keeps up to maxBytes of recently fetched data in memory,
+// in blocks of DefaultCacheBlockSize aligned within each member, so that
+// repeated ReadAt calls over the same regions are served without S3
+// requests. The least recently used blocks are evicted first.
+func WithRangeCache(maxBytes int64) Option {
+	return WithBlockCache(DefaultCacheBlockSize, maxBytes)
+}
+
+// WithBlockCache is like WithRangeCache with blocks of blockSize bytes.
+// Reads are rounded out to whole blocks, so smaller blocks fetch less
+// around small random reads while larger ones need fewer requests for
+// long reads.
+func WithBlockCache(blockSize, maxBytes int64) Option {
+	return func(o *options) error {
+		if blockSize < 1 {
+			return fmt.Errorf("invalid cache block size: %d", blockSize)
+		}
+		if maxBytes < 1 {
+			return fmt.Errorf("invalid cache size: %d", maxBytes)
+		}
+		o.cacheBlockSize = blockSize
+		o.cacheSize = maxBytes
+		return nil
+	}
+}
+
+// WithMinFetchSize rounds every GetObject range out to blocks of n bytes,
+// aligned within each member and cut short at its end, and keeps the most
+// recently fetched block in memory, so that a sequence of small reads
+// issues one request per n bytes. It has no effect with WithRangeCache or
+// WithBlockCache, whose blocks are used instead.
+func WithMinFetchSize(n int64) Option {
+	return func(o *options) error {
+		if n < 1 {
+			return fmt.Errorf("invalid min fetch size: %d", n)
+		}
+		o.minFetchSize = n
+		return nil
+	}
+}
+
+// WithSmallObjectThreshold fetches each member of at most n bytes in full,
+// with a GetObject without a range, the first time it is read, and keeps
+// it in memory for the lifetime of the seeker. All further reads of such a
+// member are served from memory, bypassing any cache. Memory use is thus
+// bounded by n times the number of small members, and reported by
+// Stats.SmallObjectBytes. Larger members are read as usual.
+func WithSmallObjectThreshold(n int64) Option {
+	return func(o *options) error {
+		if n < 1 {
+			return fmt.Errorf("invalid small object threshold: %d", n)
+		}
+		o.smallObjectThreshold = n
+		return nil
+	}
+}
+
+// WithChecksumValidation sets ChecksumMode to ENABLED on every GetObject,
+// so that S3 returns the checksum stored with an object and the SDK
+// verifies the data against whatever checksum a response carries. S3 only
+// returns checksums for the whole object, so this covers reads that fetch
+// a whole member, i.e. WriteTo from the start of a member and members
+// kept in memory by WithSmallObjectThreshold. Ranged reads, which carry no
+// checksum, are served unverified rather than failing. A mismatch fails
+// the read with a *ChecksumMismatchError.
+func WithChecksumValidation() Option {
+	return func(o *options) error {
+		o.checksumValidation = true
+		return nil
+	}
+}
+
+// WithDeduplication makes concurrent reads of the same range of a member
+// share a single GetObject, with the data copied to each caller. With
+// WithRangeCache or WithBlockCache this applies to the block-aligned
+// fetches of missing blocks, so overlapping reads of hot regions are
+// deduplicated too. The shared request is only canceled once every read
+// waiting for it has been canceled.
+func WithDeduplication() Option {
+	return func(o *options) error {
+		o.dedupe = true
+		return nil
+	}
+}
+
+// WithoutETagCheck stops sending If-Match with the ETag each member had
+// when its size was resolved, so that reads serve whatever content a key
+// holds at the time instead of failing with an *ObjectModifiedError. It is
+// equivalent to WithConsistency(ConsistencyNone).
+func WithoutETagCheck() Option {
+	return WithConsistency(ConsistencyNone)
+}
+
+// WithConsistency sets how reads detect that a member was modified after
+// its size was resolved. It defaults to ConsistencyETag.
+func WithConsistency(c Consistency) Option {
+	return func(o *options) error {
+		switch c {
+		case ConsistencyNone, ConsistencyETag, ConsistencyLastModified:
+		default:
+			return fmt.Errorf("invalid consistency: %d", c)
+		}
+		o.consistency = c
+		return nil
+	}
+}
+
+// WithAllowArchived defers the *ObjectArchivedError for a member that has
+// not been restored from an archive storage class from the constructor, or
+// the lazy resolution of its size, to the first read of the member, so
+// that a stream whose reads never touch the member can still be used.
+func WithAllowArchived() Option {
+	return func(o *options) error {
+		o.allowArchived = true
+		return nil
+	}
+}
+
+// sseCustomerKey holds the SSE-C request headers.
+type sseCustomerKey struct {
+	algorithm string
+	key       string
+	keyMD5    string
+}
+
+func newSSECustomerKey(key []byte) (*sseCustomerKey, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("invalid SSE-C key length: %d", len(key))
+	}
+	sum := md5.Sum(key)
+	return &sseCustomerKey{
+		algorithm: "AES256",
+		key:       base64.StdEncoding.EncodeToString(key),
+		keyMD5:    base64.StdEncoding.EncodeToString(sum[:]),
+	}, nil
+}
+
+// WithSSECustomerKey sends the SSE-C headers for the 256-bit AES key on
+// every HeadObject and GetObject, for objects encrypted with a
+// customer-provided key. ObjectSpec.SSECustomerKey overrides it per
+// member. Requests denied while a key is sent fail with an
+// *SSECustomerKeyError.
+func WithSSECustomerKey(key []byte) Option {
+	return func(o *options) (err error) {
+		o.sseCustomerKey, err = newSSECustomerKey(key)
+		return err
+	}
+}
+
+// WithS3Options passes optFns to every S3 call the seeker makes, e.g. to
+// override the region or add middleware for this seeker alone without a
+// separate client. Repeated uses append to the list.
+func WithS3Options(optFns ...func(*s3.Options)) Option {
+	return func(o *options) error {
+		o.s3Options = append(o.s3Options, optFns...)
+		return nil
+	}
+}
+
+// WithRequestPayer sets RequestPayer to requester on every HeadObject,
+// GetObject and ListObjectsV2 call, as required to read from Requester
+// Pays buckets. ObjectSpec.RequesterPays sets it for single members.
+func WithRequestPayer() Option {
+	return func(o *options) error {
+		o.requesterPays = true
+		return nil
+	}
+}
+
+// WithTracerProvider records an OpenTelemetry span for every GetObject
+// and HeadObject call, as a child of the span in the request's context.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(o *options) error {
+		if tp == nil {
+			return errors.New("nil tracer provider")
+		}
+		o.tracerProvider = tp
+		return nil
+	}
+}
+
+// WithNaturalKeyOrder concatenates the members in natural order of their
+// keys instead of the order given or listed: runs of digits compare by
+// their numeric value, so part-2 comes before part-10. Members with the
+// same key keep their relative order.
+func WithNaturalKeyOrder() Option {
+	return func(o *options) error {
+		o.naturalKeyOrder = true
+		return nil
+	}
+}
+
+// WithListFilter makes NewS3ReadSeekerFromPrefix only include the listed
+// keys for which keep returns true. Like WithStartAfter and
+// WithRequireNonEmpty, it is rejected by the other constructors.
+func WithListFilter(keep func(key string) bool) Option {
+	return func(o *options) error {
+		if keep == nil {
+			return errors.New("nil list filter")
+		}
+		o.listFilter = keep
+		return nil
+	}
+}
+
+// WithStartAfter makes NewS3ReadSeekerFromPrefix only include keys that
+// sort after key.
+func WithStartAfter(key string) Option {
+	return func(o *options) error {
+		o.startAfter = key
+		return nil
+	}
+}
+
+// WithRequireNonEmpty makes NewS3ReadSeekerFromPrefix fail with
+// ErrNoObjects instead of returning an empty reader when nothing matches.
+func WithRequireNonEmpty() Option {
+	return func(o *options) error {
+		o.requireNonEmpty = true
+		return nil
+	}
+}
+
+// listOption returns the name of an option that only applies to
+// NewS3ReadSeekerFromPrefix, if one was given.
+func (o *options) listOption() string {
+	switch {
+	case o.listFilter != nil:
+		return "WithListFilter"
+	case o.startAfter != "":
+		return "WithStartAfter"
+	case o.requireNonEmpty:
+		return "WithRequireNonEmpty"
+	}
+	return ""
+}
+
+// headLimit returns the maximum number of concurrent HeadObject calls.
+func (o *options) headLimit() int {
+	switch {
+	case o.headConcurrency > 0:
+		return o.headConcurrency
+	case o.readConcurrency > 0:
+		return o.readConcurrency
+	}
+	return DefaultHeadConcurrency
+}
+
+// requestContext returns the context for a single S3 request. If the
+// request timeout expires, its cause is a *requestTimeoutError.
+func (o *options) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.requestTimeout > 0 {
+		return context.WithTimeoutCause(ctx, o.requestTimeout, &requestTimeoutError{timeout: o.requestTimeout})
+	}
+	return ctx, func() {}
+}
+
+// requestTimeoutError reports that a single request exceeded the timeout
+// of WithRequestTimeout. It matches context.DeadlineExceeded, so the
+// request is retried.
+type requestTimeoutError struct {
+	timeout time.Duration
+}
+
+func (e *requestTimeoutError) Error() string {
+	return fmt.Sprintf("request timed out after %s", e.timeout)
+}
+
+func (e *requestTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
@@ -0,0 +1,139 @@
+package s3ReadSeeker
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+func TestSSECustomerKeyHeaders(t *testing.T) {
+	groupKey := bytes.Repeat([]byte{1}, 32)
+	memberKey := bytes.Repeat([]byte{2}, 32)
+	f := newFakeS3()
+	keys, all := fakeMembers(f, 100, 200, 300)
+	specs := []ObjectSpec{
+		{Key: keys[0], Size: UnknownSize},
+		{Key: keys[1], Size: UnknownSize, SSECustomerKey: memberKey},
+		{Key: keys[2], Size: UnknownSize},
+	}
+	rs, err := NewS3ReadSeekerFromSpecs(f, testBucket, specs, WithSSECustomerKey(groupKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	if got, err := io.ReadAll(rs); err != nil || !bytes.Equal(got, all) {
+		t.Fatalf("ReadAll = %d bytes, %v", len(got), err)
+	}
+	want := func(key string) (string, string) {
+		k := groupKey
+		if key == keys[1] {
+			k = memberKey
+		}
+		sum := md5.Sum(k)
+		return base64.StdEncoding.EncodeToString(k), base64.StdEncoding.EncodeToString(sum[:])
+	}
+	check := func(op, key string, algorithm, sseKey, keyMD5 *string) {
+		wantKey, wantMD5 := want(key)
+		if aws.ToString(algorithm) != "AES256" || aws.ToString(sseKey) != wantKey || aws.ToString(keyMD5) != wantMD5 {
+			t.Errorf("%s of %s carries SSE-C headers %q, %q, %q, want AES256, %q, %q",
+				op, key, aws.ToString(algorithm), aws.ToString(sseKey), aws.ToString(keyMD5), wantKey, wantMD5)
+		}
+	}
+	if len(f.heads) != len(keys) || len(f.gets) == 0 {
+		t.Fatalf("%d HeadObjects and %d GetObjects, want %d and some", len(f.heads), len(f.gets), len(keys))
+	}
+	for _, in := range f.heads {
+		check("HeadObject", aws.ToString(in.Key), in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5)
+	}
+	for _, in := range f.gets {
+		check("GetObject", aws.ToString(in.Key), in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5)
+	}
+}
+
+func TestSSECustomerKeyRejected(t *testing.T) {
+	f := newFakeS3()
+	keys, _ := fakeMembers(f, 100)
+	f.onHead = func(context.Context, *s3.HeadObjectInput) error {
+		return &smithy.GenericAPIError{Code: "AccessDenied"}
+	}
+	_, err := NewS3ReadSeeker(f, testBucket, keys, WithSSECustomerKey(bytes.Repeat([]byte{1}, 32)))
+	var keyErr *SSECustomerKeyError
+	if !errors.As(err, &keyErr) || !errors.Is(err, ErrSSECustomerKey) || keyErr.Key != keys[0] {
+		t.Fatalf("NewS3ReadSeeker() error = %v, want an *SSECustomerKeyError for %s", err, keys[0])
+	}
+	// the denial may not be about the key
+	if !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("NewS3ReadSeeker() error = %v, want it to match ErrAccessDenied", err)
+	}
+}
+
+func TestRequestPayerHeaders(t *testing.T) {
+	f := newFakeS3()
+	keys, all := fakeMembers(f, 100, 200)
+	rs, err := NewS3ReadSeeker(f, testBucket, keys, WithRequestPayer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	if got, err := io.ReadAll(rs); err != nil || !bytes.Equal(got, all) {
+		t.Fatalf("ReadAll = %d bytes, %v", len(got), err)
+	}
+	for _, in := range f.heads {
+		if in.RequestPayer != types.RequestPayerRequester {
+			t.Errorf("HeadObject of %s without RequestPayer", aws.ToString(in.Key))
+		}
+	}
+	for _, in := range f.gets {
+		if in.RequestPayer != types.RequestPayerRequester {
+			t.Errorf("GetObject of %s without RequestPayer", aws.ToString(in.Key))
+		}
+	}
+
+	rs, err = NewS3ReadSeekerFromPrefix(context.Background(), f, testBucket, "part-", WithRequestPayer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	for _, in := range f.lists {
+		if in.RequestPayer != types.RequestPayerRequester {
+			t.Errorf("ListObjectsV2 of %s without RequestPayer", aws.ToString(in.Prefix))
+		}
+	}
+}
+
+func TestRequestPayerPerMember(t *testing.T) {
+	f := newFakeS3()
+	keys, all := fakeMembers(f, 100, 200)
+	specs := []ObjectSpec{
+		{Key: keys[0], Size: UnknownSize},
+		{Key: keys[1], Size: UnknownSize, RequesterPays: true},
+	}
+	rs, err := NewS3ReadSeekerFromSpecs(f, testBucket, specs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	if got, err := io.ReadAll(rs); err != nil || !bytes.Equal(got, all) {
+		t.Fatalf("ReadAll = %d bytes, %v", len(got), err)
+	}
+	check := func(op, key string, payer types.RequestPayer) {
+		if want := key == keys[1]; (payer == types.RequestPayerRequester) != want {
+			t.Errorf("%s of %s has RequestPayer %q", op, key, payer)
+		}
+	}
+	for _, in := range f.heads {
+		check("HeadObject", aws.ToString(in.Key), in.RequestPayer)
+	}
+	for _, in := range f.gets {
+		check("GetObject", aws.ToString(in.Key), in.RequestPayer)
+	}
+}
@@ -0,0 +1,62 @@
+package s3ReadSeeker
+
+import "sort"
+
+// sortMembers applies WithNaturalKeyOrder to members.
+func (o *options) sortMembers(members []*Object) {
+	if !o.naturalKeyOrder {
+		return
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		return naturalLess(members[i].key, members[j].key)
+	})
+}
+
+// naturalLess reports whether a sorts before b in natural order.
+func naturalLess(a, b string) bool {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if !isDigit(a[i]) || !isDigit(b[j]) {
+			if a[i] != b[j] {
+				return a[i] < b[j]
+			}
+			i++
+			j++
+			continue
+		}
+		// compare the digit runs by value: ignoring leading zeros, a
+		// longer run is larger, and runs of equal length compare bytewise
+		ni, nj := digitsEnd(a, i), digitsEnd(b, j)
+		di, dj := trimZeros(a[i:ni]), trimZeros(b[j:nj])
+		if len(di) != len(dj) {
+			return len(di) < len(dj)
+		}
+		if di != dj {
+			return di < dj
+		}
+		i, j = ni, nj
+	}
+	if len(a)-i != len(b)-j {
+		return len(a)-i < len(b)-j
+	}
+	// equal up to leading zeros
+	return a < b
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func digitsEnd(s string, i int) int {
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return i
+}
+
+func trimZeros(s string) string {
+	for len(s) > 1 && s[0] == '0' {
+		s = s[1:]
+	}
+	return s
+}
@@ -0,0 +1,72 @@
+package s3ReadSeeker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3ListAPI is an S3API that can also list objects. *s3.Client implements
+// it.
+type S3ListAPI interface {
+	S3API
+	s3.ListObjectsV2APIClient
+}
+
+// *s3.Client must keep satisfying the interfaces callers pass it as.
+var (
+	_ S3API     = (*s3.Client)(nil)
+	_ S3ListAPI = (*s3.Client)(nil)
+)
+
+// NewS3ReadSeekerFromPrefix builds an S3ReadSeeker over all objects under
+// prefix, in the lexicographic order ListObjectsV2 returns them unless
+// WithNaturalKeyOrder is given. Sizes and ETags are taken from the
+// listing, so no HeadObject calls are issued. WithListFilter,
+// WithStartAfter and WithRequireNonEmpty control which keys are used, and
+// Keys returns the resulting order.
+func NewS3ReadSeekerFromPrefix(ctx context.Context, client S3ListAPI, bucketName, prefix string, opts ...Option) (rs *S3ReadSeeker, err error) {
+	rs, err = newS3ReadSeeker(client, bucketName, 0, opts)
+	if err != nil {
+		return nil, err
+	}
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucketName),
+		Prefix: aws.String(prefix),
+	}
+	if rs.opts.startAfter != "" {
+		input.StartAfter = aws.String(rs.opts.startAfter)
+	}
+	if rs.opts.requesterPays {
+		input.RequestPayer = types.RequestPayerRequester
+	}
+	paginator := s3.NewListObjectsV2Paginator(client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx, rs.opts.s3Options...)
+		if err != nil {
+			return nil, fmt.Errorf("list objects %s/%s: %w", bucketName, prefix, err)
+		}
+		for _, item := range page.Contents {
+			key := aws.ToString(item.Key)
+			if rs.opts.listFilter != nil && !rs.opts.listFilter(key) {
+				continue
+			}
+			if item.Size == nil {
+				return nil, fmt.Errorf("list objects %s/%s: missing size for %s", bucketName, prefix, key)
+			}
+			obj := rs.newObject(ObjectSpec{Key: key, Size: *item.Size})
+			obj.etag = aws.ToString(item.ETag)
+			obj.lastModified = aws.ToTime(item.LastModified)
+			rs.objectMembers = append(rs.objectMembers, obj)
+		}
+	}
+	if len(rs.objectMembers) == 0 && rs.opts.requireNonEmpty {
+		return nil, fmt.Errorf("%w under %s/%s", ErrNoObjects, bucketName, prefix)
+	}
+	rs.opts.sortMembers(rs.objectMembers)
+	rs.computeSize()
+	return rs, nil
+}
@@ -0,0 +1,130 @@
+package s3ReadSeeker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// fakeListPageSize is the page size of ListObjectsV2 on S3 and fakeS3.
+const fakeListPageSize = 1000
+
+var _ S3ListAPI = (*fakeS3)(nil)
+
+// ListObjectsV2 lists the keys in the bucket under the prefix in pages of
+// at most fakeListPageSize keys, whose continuation token is the last key
+// listed.
+func (f *fakeS3) ListObjectsV2(ctx context.Context, in *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lists = append(f.lists, in)
+	after := aws.ToString(in.StartAfter)
+	if in.ContinuationToken != nil {
+		after = *in.ContinuationToken
+	}
+	var keys []string
+	for k := range f.objects {
+		if k.bucket == aws.ToString(in.Bucket) && strings.HasPrefix(k.key, aws.ToString(in.Prefix)) && k.key > after {
+			keys = append(keys, k.key)
+		}
+	}
+	sort.Strings(keys)
+	out := &s3.ListObjectsV2Output{}
+	if len(keys) > fakeListPageSize {
+		keys = keys[:fakeListPageSize]
+		out.IsTruncated = aws.Bool(true)
+		out.NextContinuationToken = aws.String(keys[len(keys)-1])
+	}
+	for _, key := range keys {
+		obj := f.objects[fakeKey{aws.ToString(in.Bucket), key}]
+		out.Contents = append(out.Contents, types.Object{
+			Key:          aws.String(key),
+			Size:         aws.Int64(int64(len(obj.data))),
+			ETag:         aws.String(obj.etag),
+			LastModified: aws.Time(obj.lastModified),
+		})
+	}
+	out.KeyCount = aws.Int32(int32(len(out.Contents)))
+	return out, nil
+}
+
+func TestPrefixPagination(t *testing.T) {
+	const n = 2*fakeListPageSize + 500
+	f := newFakeS3()
+	var keys []string
+	for i := 0; i < n; i++ {
+		key := fmt.Sprintf("backup/%05d", i)
+		f.put(key, []byte{byte(i), byte(i >> 8)})
+		keys = append(keys, key)
+	}
+	f.put("other/00000", []byte("outside of the prefix"))
+	rs, err := NewS3ReadSeekerFromPrefix(context.Background(), f, testBucket, "backup/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	if len(f.lists) != 3 {
+		t.Errorf("ListObjectsV2 calls = %d, want 3", len(f.lists))
+	}
+	if got := f.headCount(); got != 0 {
+		t.Errorf("HeadObjects = %d, want 0", got)
+	}
+	if got := rs.Keys(); !slices.Equal(got, keys) {
+		t.Fatalf("Keys() has %d keys, want the %d under the prefix in order", len(got), len(keys))
+	}
+	if got := rs.Size(); got != 2*n {
+		t.Errorf("Size() = %d, want %d", got, 2*n)
+	}
+	// the last member, listed on the last page
+	last, p := n-1, make([]byte, 2)
+	if _, err := rs.ReadAt(p, int64(2*last)); err != nil || p[0] != byte(last) || p[1] != byte(last>>8) {
+		t.Errorf("ReadAt of the last member = %v, %v", p, err)
+	}
+}
+
+func TestPrefixStartAfterAndFilter(t *testing.T) {
+	f := newFakeS3()
+	for i := 0; i < fakeListPageSize+10; i++ {
+		f.put(fmt.Sprintf("backup/%05d", i), []byte{byte(i)})
+	}
+	even := func(key string) bool {
+		var i int
+		fmt.Sscanf(key, "backup/%d", &i)
+		return i%2 == 0
+	}
+	rs, err := NewS3ReadSeekerFromPrefix(context.Background(), f, testBucket, "backup/",
+		WithStartAfter("backup/00499"), WithListFilter(even))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	keys := rs.Keys()
+	if len(keys) != 255 || keys[0] != "backup/00500" || keys[len(keys)-1] != "backup/01008" {
+		t.Errorf("Keys() = %d keys from %s to %s, want 255 from backup/00500 to backup/01008", len(keys), keys[0], keys[len(keys)-1])
+	}
+	if _, err := NewS3ReadSeekerFromPrefix(context.Background(), f, testBucket, "none/", WithRequireNonEmpty()); !errors.Is(err, ErrNoObjects) {
+		t.Errorf("NewS3ReadSeekerFromPrefix of an empty prefix error = %v, want ErrNoObjects", err)
+	}
+}
+
+func TestListOptionsRejectedWithoutPrefix(t *testing.T) {
+	f := newFakeS3()
+	keys, _ := fakeMembers(f, 1)
+	for _, opt := range []Option{
+		WithListFilter(func(string) bool { return true }),
+		WithStartAfter("a"),
+		WithRequireNonEmpty(),
+	} {
+		if _, err := NewS3ReadSeeker(f, testBucket, keys, opt); err == nil {
+			t.Error("NewS3ReadSeeker accepted an option of NewS3ReadSeekerFromPrefix")
+		}
+	}
+}
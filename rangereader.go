@@ -0,0 +1,127 @@
+package s3ReadSeeker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// RangeReader returns a reader of the length bytes of the stream starting
+// at off. It streams each member the range covers through a single
+// GetObject of the covered part, opened when the reader reaches it, and
+// returns io.EOF after exactly length bytes. A range extending past the
+// end of the stream fails with ErrOffsetOutOfRange. Requests are issued
+// with ctx, and Close, or closing s, aborts the open one.
+// WithRequestTimeout bounds the request for each member, including the
+// reading of its part. The reader does not use or move the current offset
+// of s.
+func (s *S3ReadSeeker) RangeReader(ctx context.Context, off, length int64) (io.ReadCloser, error) {
+	if s.closed.Load() {
+		return nil, ErrClosed
+	}
+	if off < 0 {
+		return nil, fmt.Errorf("%w: %d", ErrNegativeOffset, off)
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("invalid length: %d", length)
+	}
+	ctx, stop := s.bind(ctx)
+	if err := s.resolveUntil(ctx, off+length); err != nil {
+		stop()
+		return nil, err
+	}
+	members, ends := s.resolvedMembers()
+	if off+length > totalSize(ends) {
+		stop()
+		return nil, fmt.Errorf("%w: %d bytes at %d beyond %d bytes", ErrOffsetOutOfRange, length, off, totalSize(ends))
+	}
+	r := &rangeReader{ctx: ctx, cancel: stop}
+	// find the first member ending after off
+	i := sort.Search(len(ends), func(i int) bool { return ends[i] > off })
+	for end := off + length; off < end; i++ {
+		obj := members[i]
+		local := off - (ends[i] - obj.size)
+		if local >= obj.size {
+			// empty member
+			continue
+		}
+		n := min(end-off, obj.size-local)
+		r.parts = append(r.parts, rangePart{obj: obj, off: local, n: n})
+		off += n
+	}
+	return r, nil
+}
+
+// rangePart is the part of a RangeReader range served by a single member.
+type rangePart struct {
+	obj *Object
+	off int64
+	n   int64
+}
+
+// rangeReader is the reader returned by RangeReader. parts holds the parts
+// not yet read in full, the first of which is being read through body if
+// it is open.
+type rangeReader struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	parts     []rangePart
+	body      io.ReadCloser
+	byteRange string
+	metadata  middleware.Metadata
+}
+
+func (r *rangeReader) Read(p []byte) (n int, err error) {
+	if len(r.parts) == 0 {
+		return 0, io.EOF
+	}
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	part := &r.parts[0]
+	obj := part.obj
+	if r.body == nil {
+		r.byteRange = fmt.Sprintf("bytes=%d-%d", obj.start+part.off, obj.start+part.off+part.n-1)
+		result, err := obj.openBody(r.ctx, part.off, part.n, r.byteRange, true)
+		if err != nil {
+			return 0, err
+		}
+		r.body, r.metadata = result.Body, result.ResultMetadata
+	}
+	n, err = obj.limitBody(r.ctx, r.body).Read(p[:min(int64(len(p)), part.n)])
+	obj.stats.bytesFetched.Add(int64(n))
+	obj.stats.bytesReturned.Add(int64(n))
+	part.off += int64(n)
+	part.n -= int64(n)
+	switch {
+	case part.n == 0:
+		r.closeBody()
+		r.parts = r.parts[1:]
+		return n, nil
+	case err == io.EOF:
+		err = &SizeMismatchError{Bucket: obj.bucketName, Key: obj.key, Size: obj.size, Offset: part.off, Err: io.ErrUnexpectedEOF}
+		fallthrough
+	case err != nil:
+		err = obj.bodyError(err, r.byteRange, r.metadata)
+		r.closeBody()
+		return n, err
+	}
+	return n, nil
+}
+
+func (r *rangeReader) closeBody() {
+	if r.body != nil {
+		r.body.Close()
+		r.body = nil
+	}
+}
+
+// Close aborts the open GetObject, if any. Reads after Close fail.
+func (r *rangeReader) Close() error {
+	r.cancel()
+	r.closeBody()
+	return nil
+}
@@ -0,0 +1,95 @@
+package s3ReadSeeker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func TestRangeReader(t *testing.T) {
+	f := newFakeS3()
+	keys, all := fakeMembers(f, 100, 0, 50, 200)
+	rs, err := NewS3ReadSeeker(f, testBucket, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	gets := f.getCount()
+	r, err := rs.RangeReader(context.Background(), 90, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	if err != nil || !bytes.Equal(got, all[90:290]) {
+		t.Fatalf("ReadAll = %d bytes, %v, want the 200 bytes at 90", len(got), err)
+	}
+	// one request for each non-empty member covered
+	if got := f.getCount() - gets; got != 3 {
+		t.Errorf("GetObjects = %d, want 3", got)
+	}
+	if _, err := rs.RangeReader(context.Background(), 300, 51); !errors.Is(err, ErrOffsetOutOfRange) {
+		t.Errorf("RangeReader past the end error = %v, want ErrOffsetOutOfRange", err)
+	}
+}
+
+func TestRangeReaderRequestTimeout(t *testing.T) {
+	f := newFakeS3()
+	keys, _ := fakeMembers(f, 100)
+	f.onGet = func(ctx context.Context, _ *s3.GetObjectInput) error {
+		return sleep(ctx, time.Minute)
+	}
+	rs, err := NewS3ReadSeeker(f, testBucket, keys, WithRequestTimeout(20*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	r, err := rs.RangeReader(context.Background(), 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	start := time.Now()
+	if _, err := r.Read(make([]byte, 10)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Read of a stalled request = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Read of a stalled request took %s", elapsed)
+	}
+}
+
+func TestRangeReaderRetriesTimedOutRequest(t *testing.T) {
+	f := newFakeS3()
+	keys, all := fakeMembers(f, 100)
+	var calls atomic.Int32
+	f.onGet = func(ctx context.Context, _ *s3.GetObjectInput) error {
+		if calls.Add(1) == 1 {
+			return sleep(ctx, time.Minute)
+		}
+		return nil
+	}
+	rs, err := NewS3ReadSeeker(f, testBucket, keys,
+		WithRequestTimeout(50*time.Millisecond), WithRetryConfig(RetryConfig{MaxAttempts: 3}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	r, err := rs.RangeReader(context.Background(), 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	if err != nil || !bytes.Equal(got, all) {
+		t.Fatalf("ReadAll after a timed out attempt = %d bytes, %v, want all 100", len(got), err)
+	}
+	if got := f.getCount(); got != 2 {
+		t.Errorf("GetObjects = %d, want 2", got)
+	}
+}
@@ -0,0 +1,76 @@
+package s3ReadSeeker
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// limitedReader paces reads from a GetObject body, see WithBandwidthLimit.
+type limitedReader struct {
+	ctx     context.Context
+	r       io.Reader
+	limiter *rate.Limiter
+	clock   clock
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if burst := l.limiter.Burst(); len(p) > burst {
+		p = p[:burst]
+	}
+	n, err := l.r.Read(p)
+	if n > 0 {
+		if werr := l.wait(n); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+// wait is like the limiter's WaitN for n bytes, but on l.clock.
+func (l *limitedReader) wait(n int) error {
+	now := l.clock.Now()
+	r := l.limiter.ReserveN(now, n)
+	if err := l.clock.Sleep(l.ctx, r.DelayFrom(now)); err != nil {
+		// return the tokens for other readers
+		r.CancelAt(l.clock.Now())
+		return err
+	}
+	return nil
+}
+
+// limitBody returns body paced by the bandwidth limit, if any. Waits end
+// when ctx is done.
+func (sh *shared) limitBody(ctx context.Context, body io.Reader) io.Reader {
+	if sh.limiter == nil {
+		return body
+	}
+	return &limitedReader{ctx: ctx, r: body, limiter: sh.limiter, clock: sh.clock}
+}
+
+// clock is the time source of the bandwidth limiter, which tests replace.
+type clock interface {
+	Now() time.Time
+	// Sleep waits for d, or until ctx is done.
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,79 @@
+package s3ReadSeeker
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock whose Sleep advances its time instead of waiting.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d > 0 {
+		c.now = c.now.Add(d)
+	}
+	return nil
+}
+
+func TestBandwidthLimit(t *testing.T) {
+	const (
+		size  = 10 << 20
+		limit = 1 << 20
+	)
+	f := newFakeS3()
+	keys, all := fakeMembers(f, size)
+	rs, err := NewS3ReadSeekerFromSizes(f, testBucket, keys, []int64{size}, WithBandwidthLimit(limit))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	rs.clock = clock
+	start := clock.Now()
+	p := make([]byte, size)
+	if n, err := rs.ReadAt(p, 0); err != nil || n != size || !bytes.Equal(p, all) {
+		t.Fatalf("ReadAt = %d, %v, want %d bytes", n, err, size)
+	}
+	// the first second's worth is the initial burst
+	elapsed := clock.Now().Sub(start)
+	if elapsed < 9*time.Second || elapsed > 10*time.Second {
+		t.Errorf("reading %d bytes at %d bytes/s took %s, want about 10s", size, limit, elapsed)
+	}
+}
+
+func TestBandwidthLimitCanceled(t *testing.T) {
+	f := newFakeS3()
+	keys, _ := fakeMembers(f, 4<<10)
+	rs, err := NewS3ReadSeeker(f, testBucket, keys, WithBandwidthLimit(1<<10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	// 4 seconds' worth at the limit
+	if _, err := rs.ReadAtContext(ctx, make([]byte, 4<<10), 0); err == nil {
+		t.Fatal("ReadAtContext succeeded despite the deadline")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("canceled ReadAtContext waited %s for the limiter", elapsed)
+	}
+}
@@ -0,0 +1,115 @@
+package s3ReadSeeker
+
+import (
+	"context"
+	"io"
+)
+
+// prefetch is a read-ahead window being fetched in the background.
+type prefetch struct {
+	off    int64
+	buf    []byte
+	err    error
+	done   chan struct{}
+	cancel context.CancelFunc
+}
+
+// bufferContains reports whether off lies within the read-ahead buffer.
+// The caller must hold mu.
+func (s *S3ReadSeeker) bufferContains(off int64) bool {
+	return off >= s.bufOffset && off < s.bufOffset+int64(len(s.buf))
+}
+
+// readBuffered reads into p from the current offset, refilling the
+// read-ahead buffer with a single ReadAt of size bytes when the offset
+// lies outside of it. Empty reads and reads at least as large as the
+// buffer bypass it. The caller must hold mu.
+func (s *S3ReadSeeker) readBuffered(ctx context.Context, p []byte, size int) (n int, err error) {
+	if len(p) == 0 || len(p) >= size {
+		return s.readAt(ctx, p, s.globalOffset)
+	}
+	if !s.bufferContains(s.globalOffset) {
+		sequential := s.globalOffset == s.bufOffset+int64(len(s.buf))
+		if !s.takePrefetch(ctx) {
+			if cap(s.buf) != size {
+				s.buf = make([]byte, size)
+			}
+			m, err := s.readAt(ctx, s.buf[:cap(s.buf)], s.globalOffset)
+			if err != nil && (err != io.EOF || m == 0) {
+				s.buf = s.buf[:0]
+				return 0, err
+			}
+			s.buf = s.buf[:m]
+			s.bufOffset = s.globalOffset
+		}
+		if s.opts.prefetch && sequential && len(s.buf) == cap(s.buf) {
+			s.startPrefetch()
+		}
+	}
+	return copy(p, s.buf[s.globalOffset-s.bufOffset:]), nil
+}
+
+// startPrefetch tops up the queue of prefetched windows following the
+// buffer to the prefetch depth. The caller must hold mu.
+func (s *S3ReadSeeker) startPrefetch() {
+	off := s.bufOffset + int64(len(s.buf))
+	if n := len(s.prefetches); n > 0 {
+		off = s.prefetches[n-1].off + int64(s.opts.readAheadSize)
+	}
+	for len(s.prefetches) < s.opts.prefetchDepth {
+		ctx, cancel := context.WithCancel(s.context())
+		next := &prefetch{
+			off:    off,
+			buf:    make([]byte, s.opts.readAheadSize),
+			done:   make(chan struct{}),
+			cancel: cancel,
+		}
+		go func() {
+			defer close(next.done)
+			m, err := s.readAt(ctx, next.buf, next.off)
+			if err == io.EOF && m > 0 {
+				err = nil
+			}
+			next.buf, next.err = next.buf[:m], err
+		}()
+		s.prefetches = append(s.prefetches, next)
+		off += int64(s.opts.readAheadSize)
+	}
+}
+
+// takePrefetch waits for the first prefetched window if it starts at the
+// current offset and makes it the buffer, or else cancels all prefetches.
+// It reports false if there is none or it failed, in which case the caller
+// reads the window itself so that errors are reported for the Read's
+// context. The caller must hold mu.
+func (s *S3ReadSeeker) takePrefetch(ctx context.Context) bool {
+	if len(s.prefetches) == 0 {
+		return false
+	}
+	next := s.prefetches[0]
+	if next.off != s.globalOffset {
+		s.stopPrefetch()
+		return false
+	}
+	select {
+	case <-next.done:
+	case <-ctx.Done():
+		return false
+	}
+	next.cancel()
+	s.prefetches = append(s.prefetches[:0], s.prefetches[1:]...)
+	if next.err != nil {
+		s.stopPrefetch()
+		return false
+	}
+	s.buf, s.bufOffset = next.buf, next.off
+	return true
+}
+
+// stopPrefetch cancels all pending prefetches. The caller must hold mu.
+func (s *S3ReadSeeker) stopPrefetch() {
+	for _, next := range s.prefetches {
+		next.cancel()
+	}
+	s.prefetches = s.prefetches[:0]
+}
@@ -0,0 +1,146 @@
+package s3ReadSeeker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// waitFor polls cond until it holds or a generous timeout passes.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(10 * time.Second); !cond(); {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestReadAheadPrefetchesNextWindow(t *testing.T) {
+	const window = 1000
+	f := newFakeS3()
+	keys, all := fakeMembers(f, 3000, 1500)
+	rs, err := NewS3ReadSeeker(f, testBucket, keys, WithReadAhead(window))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	p := make([]byte, 100)
+	if _, err := io.ReadFull(rs, p); err != nil || !bytes.Equal(p, all[:100]) {
+		t.Fatalf("first Read = %v", err)
+	}
+	// the window after the first is fetched in the background
+	waitFor(t, "the prefetch", func() bool { return f.getCount() == 2 })
+	if got := aws.ToString(f.gets[1].Range); got != "bytes=1000-1999" {
+		t.Errorf("Range of the prefetch = %q, want bytes=1000-1999", got)
+	}
+	// reading into the second window takes the prefetch, and prefetches
+	// the third
+	got, err := io.ReadAll(io.LimitReader(rs, 1500))
+	if err != nil || !bytes.Equal(got, all[100:1600]) {
+		t.Fatalf("Read across the windows = %d bytes, %v", len(got), err)
+	}
+	waitFor(t, "the next prefetch", func() bool { return f.getCount() == 3 })
+
+	// ReadAt bypasses the windows
+	if _, err := rs.ReadAt(p, 1200); err != nil || !bytes.Equal(p, all[1200:1300]) {
+		t.Fatalf("ReadAt within a prefetched window = %v", err)
+	}
+	if got := f.getCount(); got != 4 {
+		t.Errorf("GetObjects after ReadAt = %d, want 4", got)
+	}
+	got, err = io.ReadAll(rs)
+	if err != nil || !bytes.Equal(got, all[1600:]) {
+		t.Fatalf("ReadAll of the rest = %d bytes, %v", len(got), err)
+	}
+}
+
+func TestReadAheadCanceledBySeek(t *testing.T) {
+	f := newFakeS3()
+	keys, all := fakeMembers(f, 5000)
+	stalled := make(chan error, 1)
+	f.onGet = func(ctx context.Context, in *s3.GetObjectInput) error {
+		if aws.ToString(in.Range) != "bytes=1000-1999" {
+			return nil
+		}
+		err := sleep(ctx, time.Minute)
+		stalled <- err
+		return err
+	}
+	rs, err := NewS3ReadSeeker(f, testBucket, keys, WithReadAhead(1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	p := make([]byte, 100)
+	if _, err := io.ReadFull(rs, p); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the prefetch", func() bool { return f.getCount() == 2 })
+	if _, err := rs.Seek(3000, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-stalled:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("prefetch ended with %v, want context.Canceled", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Seek did not cancel the prefetch")
+	}
+	if _, err := io.ReadFull(rs, p); err != nil || !bytes.Equal(p, all[3000:3100]) {
+		t.Fatalf("Read after the Seek = %v", err)
+	}
+}
+
+// BenchmarkSequentialRead reads a member in 32 KiB Reads, as io.Copy would
+// without WriteTo, from a client with 2ms of latency per request.
+func BenchmarkSequentialRead(b *testing.B) {
+	const size, window = 4 << 20, 256 << 10
+	for _, bc := range []struct {
+		name string
+		opts []Option
+	}{
+		{"Plain", nil},
+		{"ReadAheadSize", []Option{WithReadAheadSize(window)}},
+		{"ReadAhead", []Option{WithReadAhead(window)}},
+		{"ReadAheadDepth4", []Option{WithReadAhead(window), WithPrefetchDepth(4)}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			f := newFakeS3()
+			keys, _ := fakeMembers(f, size)
+			f.onGet = func(ctx context.Context, _ *s3.GetObjectInput) error {
+				return sleep(ctx, 2*time.Millisecond)
+			}
+			rs, err := NewS3ReadSeeker(f, testBucket, keys, bc.opts...)
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer rs.Close()
+			p := make([]byte, 32<<10)
+			b.SetBytes(size)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := rs.Seek(0, io.SeekStart); err != nil {
+					b.Fatal(err)
+				}
+				for {
+					_, err := rs.Read(p)
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						b.Fatalf("Read = %v", err)
+					}
+				}
+			}
+		})
+	}
+}
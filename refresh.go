@@ -0,0 +1,91 @@
+package s3ReadSeeker
+
+import (
+	"context"
+)
+
+// Refresh re-resolves the sizes and ETags of the members with HeadObject,
+// e.g. after the last member was overwritten with a longer version. If
+// keys are given, only the members with those keys are refreshed. Members
+// whose sizes have not been resolved yet with WithLazySizes are left to be
+// resolved when needed. If a HeadObject fails, s is unchanged.
+//
+// The refreshed members replace the old ones at once: a ReadAt running
+// concurrently reads either the old or the new members, and a read of an
+// old member that was overwritten fails with an *ObjectModifiedError. The
+// range cache and the read-ahead buffer are cleared. If the stream has
+// shrunk below the current offset, the offset is moved to its new end,
+// where Read returns io.EOF. With WithLazySizes this only happens once
+// every member is resolved, as the members not resolved yet may still
+// reach the offset.
+func (s *S3ReadSeeker) Refresh(ctx context.Context, keys ...string) error {
+	if s.closed.Load() {
+		return ErrClosed
+	}
+	var only map[string]bool
+	if len(keys) > 0 {
+		only = make(map[string]bool, len(keys))
+		for _, key := range keys {
+			only[key] = true
+		}
+	}
+	members, _ := s.members()
+	replaced := make(map[*Object]*Object)
+	var fresh []*Object
+	// members may be being resolved, so resolved is read under resolveMu
+	s.resolveMu.Lock()
+	for _, obj := range members {
+		if !obj.resolved || only != nil && !only[obj.key] {
+			continue
+		}
+		r := obj.refreshed()
+		replaced[obj] = r
+		fresh = append(fresh, r)
+	}
+	s.resolveMu.Unlock()
+	if err := headObjects(ctx, fresh, s.opts.headLimit()); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closeStream()
+	s.stopPrefetch()
+	s.buf = s.buf[:0]
+
+	s.resolveMu.Lock()
+	defer s.resolveMu.Unlock()
+	s.sizeMu.Lock()
+	defer s.sizeMu.Unlock()
+	// copy, so that readers holding the old members keep a consistent view
+	members = make([]*Object, len(s.objectMembers))
+	for i, obj := range s.objectMembers {
+		if r, ok := replaced[obj]; ok {
+			obj = r
+		}
+		members[i] = obj
+	}
+	s.objectMembers = members
+	s.computeSize()
+	if size := totalSize(s.ends); len(s.ends) == len(s.objectMembers) && s.globalOffset > size {
+		s.globalOffset = size
+	}
+	if s.cache != nil {
+		s.cache.reset()
+	}
+	return nil
+}
+
+// refreshed returns an unresolved copy of o for Refresh.
+func (o *Object) refreshed() *Object {
+	return &Object{
+		client:         o.client,
+		bucketName:     o.bucketName,
+		key:            o.key,
+		versionId:      o.versionId,
+		start:          o.start,
+		sseCustomerKey: o.sseCustomerKey,
+		requesterPays:  o.requesterPays,
+		shared:         o.shared,
+	}
+}
@@ -0,0 +1,34 @@
+package s3ReadSeeker
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+)
+
+func TestRefreshClampsOffsetOfShrunkStream(t *testing.T) {
+	f := newFakeS3()
+	keys, _ := fakeMembers(f, 100, 100)
+	rs, err := NewS3ReadSeeker(f, testBucket, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	if _, err := rs.Seek(180, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	f.put(keys[1], bytes.Repeat([]byte{'x'}, 30))
+	if err := rs.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := rs.Offset(); got != 130 {
+		t.Errorf("Offset() after the stream shrank to 130 = %d", got)
+	}
+	if off, err := rs.Seek(0, io.SeekCurrent); err != nil || off != 130 {
+		t.Errorf("Seek(0, io.SeekCurrent) = %d, %v, want 130", off, err)
+	}
+	if n, err := rs.Read(make([]byte, 10)); n != 0 || err != io.EOF {
+		t.Errorf("Read at the new end = %d, %v, want io.EOF", n, err)
+	}
+}
@@ -0,0 +1,51 @@
+package s3ReadSeeker
+
+// Reset makes s read the concatenation of keyGroup in its bucket from
+// offset 0, as if built anew by NewS3ReadSeeker with the same options,
+// while reusing its read-ahead buffer and other allocations. Unknown sizes
+// are resolved with the stored context. The range cache is cleared, while
+// Stats keep counting. If Reset fails, s is unchanged.
+//
+// Reset must not be called concurrently with other methods of s, and a
+// closed seeker cannot be reset.
+func (s *S3ReadSeeker) Reset(keyGroup []string) error {
+	return s.reset(keySpecs(keyGroup))
+}
+
+// ResetFromSizes is like Reset for keys of known sizes, see
+// NewS3ReadSeekerFromSizes.
+func (s *S3ReadSeeker) ResetFromSizes(keyGroup []string, sizes []int64) error {
+	specs, err := sizeSpecs(keyGroup, sizes)
+	if err != nil {
+		return err
+	}
+	return s.reset(specs)
+}
+
+func (s *S3ReadSeeker) reset(specs []ObjectSpec) error {
+	if s.closed.Load() {
+		return ErrClosed
+	}
+	members, err := s.newMembers(s.context(), specs)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closeStream()
+	s.stopPrefetch()
+	s.buf = s.buf[:0]
+	s.bufOffset = 0
+	s.globalOffset = 0
+
+	s.resolveMu.Lock()
+	defer s.resolveMu.Unlock()
+	s.sizeMu.Lock()
+	defer s.sizeMu.Unlock()
+	s.objectMembers = members
+	s.computeSize()
+	if s.cache != nil {
+		s.cache.reset()
+	}
+	return nil
+}
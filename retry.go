@@ -0,0 +1,79 @@
+package s3ReadSeeker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/aws/smithy-go"
+)
+
+// retry calls fn with the 1-based attempt number until it succeeds, fails
+// with an error that is not retryable, ctx is done or the configured
+// attempts have been made. Errors after more than one attempt are
+// annotated with the attempt count.
+func (sh *shared) retry(ctx context.Context, fn func(attempt int) error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn(attempt)
+		if err == nil {
+			return nil
+		}
+		if attempt >= sh.opts.retryConfig.MaxAttempts || !isRetryable(err) || ctx.Err() != nil {
+			if attempt > 1 {
+				return fmt.Errorf("after %d attempts: %w", attempt, err)
+			}
+			return err
+		}
+		timer := time.NewTimer(sh.opts.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("after %d attempts: %w", attempt, errors.Join(err, ctx.Err()))
+		case <-timer.C:
+		}
+		sh.stats.retries.Add(1)
+	}
+}
+
+// backoff returns the delay before the retry following attempt, using
+// exponential backoff with full jitter.
+func (o *options) backoff(attempt int) time.Duration {
+	if attempt > 32 {
+		attempt = 32
+	}
+	d := o.retryConfig.BaseDelay << (attempt - 1)
+	if d <= 0 {
+		return 0
+	}
+	if o.retryConfig.MaxDelay > 0 && d > o.retryConfig.MaxDelay {
+		d = o.retryConfig.MaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(d)))
+}
+
+// isRetryable reports whether err is a transient failure worth retrying.
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		// a per-request timeout, the caller's context is checked separately
+		return true
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "SlowDown", "RequestTimeout", "InternalError", "ServiceUnavailable":
+			return true
+		}
+	}
+	var respErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &respErr) {
+		return respErr.HTTPStatusCode() >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -2,119 +2,1047 @@ package s3ReadSeeker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	"golang.org/x/sync/errgroup"
 )
 
+// S3API is the subset of the S3 client used by S3ReadSeeker. *s3.Client
+// implements it; tests can supply a fake instead.
+type S3API interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+}
+
+// UnknownSize is the Size of an ObjectSpec whose size should be resolved
+// with HeadObject.
+const UnknownSize = -1
+
+// ObjectSpec describes a member object. Bucket defaults to the bucket
+// given to the constructor. If Size is UnknownSize it is resolved with
+// HeadObject, otherwise it is trusted as is. A non-empty VersionId pins
+// every request for the member to that object version. A non-nil
+// SSECustomerKey overrides WithSSECustomerKey for the member, and
+// RequesterPays applies WithRequestPayer to it alone, e.g. for the members
+// of a group that live in a Requester Pays bucket. A positive Start trims
+// that many leading bytes of the object from the stream, e.g. to resume
+// from the middle of a partly consumed member; Size is still the size of
+// the whole object.
+type ObjectSpec struct {
+	Bucket         string
+	Key            string
+	Size           int64
+	VersionId      string
+	SSECustomerKey []byte
+	RequesterPays  bool
+	Start          int64
+}
+
 type Object struct {
-	client     *s3.Client
+	client     S3API
 	bucketName string
 	key        string
-	size       int64
-	offset     int64
+	versionId  string
+	// size is the number of bytes of the object in the stream, i.e.
+	// without the first start bytes
+	size  int64
+	start int64
+	// etag and lastModified are those the object had when size was
+	// resolved, see WithConsistency
+	etag         string
+	lastModified time.Time
+	// sseCustomerKey is the SSE-C key sent with every request, if any
+	sseCustomerKey *sseCustomerKey
+	requesterPays  bool
+	// resolved is set once size is known
+	resolved bool
+	// archived is set with WithAllowArchived if the HeadObject found o
+	// archived, and returned by reads of o
+	archived *ObjectArchivedError
+	// body is the open GetObject body used by Read with WithStreaming,
+	// bodyOffset the offset of its next byte and bodyRange and
+	// bodyMetadata the range and metadata of its response. All are guarded
+	// by the seeker's mu.
+	body         io.ReadCloser
+	bodyOffset   int64
+	bodyRange    string
+	bodyMetadata middleware.Metadata
+	// data is the full content of a small object once fetched, see
+	// WithSmallObjectThreshold. It is guarded by dataMu.
+	dataMu  sync.Mutex
+	data    []byte
+	breaker breaker
+	*shared
 }
 
 func (o *Object) ReadAt(p []byte, off int64) (n int, err error) {
-	byteRange := fmt.Sprintf("bytes=%d-%d", off, off+int64(len(p))-1)
+	return o.ReadAtContext(context.Background(), p, off)
+}
+
+// ReadAtContext is like ReadAt but issues the GetObject with ctx. If ctx is
+// cancelled mid-read, its error or cause is returned wrapped with the key
+// and range. A read past the end of o is cut short at the end and returns
+// io.EOF, so the Range sent never extends past the object.
+func (o *Object) ReadAtContext(ctx context.Context, p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, fmt.Errorf("%w: %d", ErrNegativeOffset, off)
+	}
+	if len(p) == 0 {
+		// an empty Range is invalid, so no request is issued
+		if off >= o.size {
+			return 0, io.EOF
+		}
+		return 0, nil
+	}
+	if o.archived != nil {
+		return 0, o.archived
+	}
+	if off >= o.size {
+		return 0, io.EOF
+	}
+	if off+int64(len(p)) > o.size {
+		// never request a range past the end, which strict stores reject
+		n, err = o.ReadAtContext(ctx, p[:o.size-off], off)
+		if err == nil {
+			err = io.EOF
+		}
+		return n, err
+	}
+	switch {
+	case o.isSmall():
+		return o.readSmall(ctx, p, off)
+	case o.cache != nil:
+		return o.readCached(ctx, p, off)
+	}
+	return o.fetch(ctx, p, off)
+}
+
+// fetch reads len(p) bytes at off with a ranged GetObject, bypassing the
+// cache. With WithDeduplication, concurrent fetches of the same range
+// share one request, which the breaker sees once.
+func (o *Object) fetch(ctx context.Context, p []byte, off int64) (n int, err error) {
+	if o.flights != nil {
+		return o.fetchShared(ctx, p, off)
+	}
+	if err := o.allow(); err != nil {
+		return 0, err
+	}
+	defer func() { o.record(ctx, err) }()
+	return o.fetchRange(ctx, p, off)
+}
+
+// maxResumes bounds the follow-up requests fetchRange issues for the rest
+// of a range whose response body ended early.
+const maxResumes = 3
+
+// fetchRange issues the ranged GetObject for fetch. A body that ends early
+// after delivering some bytes, as when the connection drops, is resumed
+// with a request for the missing suffix of the range, so that either all
+// of p is read or an error is returned.
+func (o *Object) fetchRange(ctx context.Context, p []byte, off int64) (n int, err error) {
+	for resumes := 0; ; resumes++ {
+		m, err := o.fetchRetrying(ctx, p[n:], off+int64(n))
+		n += m
+		if err == nil || m == 0 || resumes == maxResumes || !errors.Is(err, io.ErrUnexpectedEOF) {
+			return n, err
+		}
+	}
+}
+
+// fetchRetrying issues a single ranged GetObject for fetchRange, retrying
+// transient errors.
+func (o *Object) fetchRetrying(ctx context.Context, p []byte, off int64) (n int, err error) {
+	byteRange := fmt.Sprintf("bytes=%d-%d", o.start+off, o.start+off+int64(len(p))-1)
+	err = o.retry(ctx, func(attempt int) error {
+		ctx, cancel := o.opts.requestContext(ctx)
+		defer cancel()
+		ctx, span := o.startSpan(ctx, "GetObject", off, int64(len(p)), attempt)
+		n, err = o.readRangeHedged(ctx, p, off, byteRange)
+		endSpan(span, int64(n), err)
+		return err
+	})
+	return n, err
+}
+
+// rangeFrom returns the Range header for a GetObject of o from off to its
+// end, or "" if that is the whole object.
+func (o *Object) rangeFrom(off int64) string {
+	if o.start+off == 0 {
+		return ""
+	}
+	return fmt.Sprintf("bytes=%d-", o.start+off)
+}
+
+// getObjectInput returns the input for a GetObject of byteRange, or of
+// the whole object if byteRange is empty.
+func (o *Object) getObjectInput(byteRange string) *s3.GetObjectInput {
 	input := &s3.GetObjectInput{
 		Bucket: aws.String(o.bucketName),
 		Key:    aws.String(o.key),
-		Range:  aws.String(byteRange),
 	}
-	result, err := o.client.GetObject(context.TODO(), input)
+	if byteRange != "" {
+		input.Range = aws.String(byteRange)
+	}
+	if o.versionId != "" {
+		input.VersionId = aws.String(o.versionId)
+	}
+	if sse := o.sseCustomerKey; sse != nil {
+		input.SSECustomerAlgorithm = aws.String(sse.algorithm)
+		input.SSECustomerKey = aws.String(sse.key)
+		input.SSECustomerKeyMD5 = aws.String(sse.keyMD5)
+	}
+	if o.requesterPays {
+		input.RequestPayer = types.RequestPayerRequester
+	}
+	if o.pinned() {
+		if o.opts.consistency == ConsistencyETag {
+			input.IfMatch = aws.String(o.etag)
+		} else {
+			input.IfUnmodifiedSince = aws.Time(o.lastModified)
+		}
+	}
+	if o.opts.checksumValidation {
+		input.ChecksumMode = types.ChecksumModeEnabled
+	}
+	return input
+}
+
+// getObjectError translates a GetObject error for a read at off into the
+// package's error types.
+func (o *Object) getObjectError(err error, off int64, byteRange string) error {
+	err = o.invalidObjectStateError(o.apiError(err))
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "InvalidRange":
+			err = &InvalidRangeError{Bucket: o.bucketName, Key: o.key, Range: byteRange, Err: err}
+			return &SizeMismatchError{Bucket: o.bucketName, Key: o.key, Size: o.size, Offset: off, Err: err}
+		case "PreconditionFailed":
+			modErr := &ObjectModifiedError{Bucket: o.bucketName, Key: o.key, Offset: off, Err: err}
+			if o.opts.consistency == ConsistencyLastModified {
+				modErr.LastModified = o.lastModified
+			} else {
+				modErr.ETag = o.etag
+			}
+			return modErr
+		}
+	}
+	return err
+}
+
+func (o *Object) readRange(ctx context.Context, p []byte, off int64, byteRange string) (n int, err error) {
+	input := o.getObjectInput(byteRange)
+	var result *s3.GetObjectOutput
+	start := time.Now()
+	defer func() {
+		o.reportRequest(ctx, "GetObject", byteRange, start, result, int64(n), err)
+	}()
+	release, err := o.acquire(ctx)
 	if err != nil {
-		return 0, err
+		return 0, fmt.Errorf("get object %s %s: %w", o.path(), byteRange, err)
+	}
+	defer release()
+	o.stats.getRequests.Add(1)
+	result, err = o.client.GetObject(ctx, input, o.opts.s3Options...)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, fmt.Errorf("get object %s %s: %w", o.path(), byteRange, context.Cause(ctx))
+		}
+		return 0, fmt.Errorf("get object %s %s: %w", o.path(), byteRange, o.getObjectError(err, off, byteRange))
 	}
 	defer result.Body.Close()
-	return io.ReadFull(result.Body, p)
+	if err := o.checkContentRange(byteRange, result); err != nil {
+		return 0, o.bodyError(err, byteRange, result.ResultMetadata)
+	}
+	n, err = io.ReadFull(o.limitBody(ctx, result.Body), p)
+	o.stats.bytesFetched.Add(int64(n))
+	if err != nil {
+		if ctx.Err() != nil {
+			return n, fmt.Errorf("get object %s %s: %w", o.path(), byteRange, context.Cause(ctx))
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = &SizeMismatchError{Bucket: o.bucketName, Key: o.key, Size: o.size, Offset: off + int64(n), Err: err}
+		}
+		return n, o.bodyError(o.checksumError(err, byteRange), byteRange, result.ResultMetadata)
+	}
+	if byteRange == "" {
+		if err = o.verifyBody(result.Body, byteRange); err != nil {
+			err = o.bodyError(err, byteRange, result.ResultMetadata)
+		}
+	}
+	return n, err
+}
 
+// writeTo streams o from off to its end into w with a single GetObject.
+// Opening it is retried like any other, but once bytes have been written
+// to w a failure is returned as is.
+func (o *Object) writeTo(ctx context.Context, w io.Writer, off int64) (n int64, err error) {
+	byteRange := o.rangeFrom(off)
+	result, err := o.openBody(ctx, off, o.size-off, byteRange, true)
+	if err != nil {
+		return 0, err
+	}
+	defer result.Body.Close()
+	n, err = io.CopyN(w, o.limitBody(ctx, result.Body), o.size-off)
+	o.stats.bytesFetched.Add(n)
+	switch {
+	case err != nil && ctx.Err() != nil:
+		return n, fmt.Errorf("get object %s %s: %w", o.path(), byteRange, context.Cause(ctx))
+	case err == io.EOF:
+		err = &SizeMismatchError{Bucket: o.bucketName, Key: o.key, Size: o.size, Offset: off + n, Err: err}
+	case err != nil:
+		err = o.checksumError(err, byteRange)
+	case byteRange == "":
+		err = o.verifyBody(result.Body, byteRange)
+	}
+	if err != nil {
+		return n, o.bodyError(err, byteRange, result.ResultMetadata)
+	}
+	return n, nil
 }
 
+var (
+	_ io.ReadSeekCloser = (*S3ReadSeeker)(nil)
+	_ io.ReaderAt       = (*S3ReadSeeker)(nil)
+	_ io.WriterTo       = (*S3ReadSeeker)(nil)
+	_ io.ByteReader     = (*S3ReadSeeker)(nil)
+)
+
+// S3ReadSeeker reads a sequence of S3 objects, its members, as one
+// concatenated stream.
+//
+// ReadAt, ReadAtContext, Section readers, Size, Stats and Close are safe
+// for concurrent use, including concurrently with Read, Seek and WriteTo,
+// as io.ReaderAt consumers such as archive/zip expect. The lazily resolved
+// sizes and the block cache they share are synchronized internally, and
+// ReadAt never touches the current offset or the read-ahead buffer.
+// Read, ReadByte, Seek and WriteTo serialize with each other, as they all
+// move the current offset.
 type S3ReadSeeker struct {
-	client        *s3.Client
+	client        S3API
 	bucketName    string
 	objectMembers []*Object
 	globalOffset  int64
 	mu            sync.Mutex
+	*shared
+
+	// buf holds the read-ahead window starting at bufOffset, see
+	// WithReadAheadSize, and prefetches the windows being fetched after
+	// it in order, see WithReadAhead. All are guarded by mu.
+	buf        []byte
+	bufOffset  int64
+	prefetches []*prefetch
+
+	// stream is the member whose body is open, see WithStreaming, and
+	// streamCtx the context of the Read that opened it. streamStop ends
+	// the binding of the body's context to the seeker, see bind. All are
+	// guarded by mu.
+	stream     *Object
+	streamCtx  context.Context
+	streamStop context.CancelFunc
+
+	// ends holds the cumulative end offset of each leading member whose
+	// size is known, so ends[i]-objectMembers[i].size is where member i
+	// starts in the concatenated stream. It only covers fewer than all
+	// members with WithLazySizes or after AppendKeys. ends and, once the
+	// seeker is built, objectMembers are guarded by sizeMu and only ever
+	// appended to; resolveMu serializes the HeadObject calls that extend
+	// ends and AppendKeys.
+	sizeMu    sync.Mutex
+	resolveMu sync.Mutex
+	ends      []int64
+	// keyIndex maps each key to the index of its first member. It is
+	// guarded by sizeMu.
+	keyIndex map[string]int
+
+	// ctx is used by Read and ReadAt, see SetContext. cancel is called
+	// by Close.
+	ctxMu  sync.RWMutex
+	ctx    context.Context
+	cancel context.CancelFunc
+	closed atomic.Bool
+	// lifetime is canceled by Close, which also cancels the calls running
+	// with contexts of their own, see bind.
+	lifetime    context.Context
+	endLifetime context.CancelFunc
+}
+
+func NewS3ReadSeeker(client S3API, bucketName string, keyGroup []string, opts ...Option) (rs *S3ReadSeeker, err error) {
+	return NewS3ReadSeekerWithContext(context.Background(), client, bucketName, keyGroup, opts...)
+}
+
+// NewS3ReadSeekerWithContext is like NewS3ReadSeeker but issues the
+// HeadObject calls with ctx.
+func NewS3ReadSeekerWithContext(ctx context.Context, client S3API, bucketName string, keyGroup []string, opts ...Option) (rs *S3ReadSeeker, err error) {
+	return NewS3ReadSeekerFromSpecsWithContext(ctx, client, bucketName, keySpecs(keyGroup), opts...)
+}
+
+// keySpecs returns the specs for keys of unknown size.
+func keySpecs(keyGroup []string) []ObjectSpec {
+	specs := make([]ObjectSpec, len(keyGroup))
+	for n, key := range keyGroup {
+		specs[n] = ObjectSpec{Key: key, Size: UnknownSize}
+	}
+	return specs
+}
+
+// NewS3ReadSeekerFromSpecs builds an S3ReadSeeker from member specs. No
+// HeadObject calls are issued for members whose size is given. bucketName
+// may be empty if every spec names its own bucket.
+func NewS3ReadSeekerFromSpecs(client S3API, bucketName string, specs []ObjectSpec, opts ...Option) (rs *S3ReadSeeker, err error) {
+	return NewS3ReadSeekerFromSpecsWithContext(context.Background(), client, bucketName, specs, opts...)
+}
+
+// NewS3ReadSeekerFromSpecsWithContext is like NewS3ReadSeekerFromSpecs but
+// issues the HeadObject calls with ctx.
+func NewS3ReadSeekerFromSpecsWithContext(ctx context.Context, client S3API, bucketName string, specs []ObjectSpec, opts ...Option) (rs *S3ReadSeeker, err error) {
+	rs, err = newS3ReadSeeker(client, bucketName, 0, opts)
+	if err != nil {
+		return nil, err
+	}
+	if name := rs.opts.listOption(); name != "" {
+		return nil, fmt.Errorf("%s only applies to NewS3ReadSeekerFromPrefix", name)
+	}
+	if rs.objectMembers, err = rs.newMembers(ctx, specs); err != nil {
+		return nil, err
+	}
+	rs.computeSize()
+	return rs, nil
+}
+
+// newMembers builds the members for specs in stream order and, unless
+// WithLazySizes is given, resolves their unknown sizes.
+func (s *S3ReadSeeker) newMembers(ctx context.Context, specs []ObjectSpec) (members []*Object, err error) {
+	members = make([]*Object, len(specs))
+	var pending []*Object
+	for n, spec := range specs {
+		if spec.Bucket == "" && s.bucketName == "" {
+			return nil, fmt.Errorf("no bucket for object %s", spec.Key)
+		}
+		if spec.Size < 0 && spec.Size != UnknownSize {
+			return nil, fmt.Errorf("invalid size %d for object %s", spec.Size, spec.Key)
+		}
+		if spec.Start < 0 || spec.Size != UnknownSize && spec.Start > spec.Size {
+			return nil, fmt.Errorf("invalid start %d for object %s", spec.Start, spec.Key)
+		}
+		obj := s.newObject(spec)
+		if spec.SSECustomerKey != nil {
+			if obj.sseCustomerKey, err = newSSECustomerKey(spec.SSECustomerKey); err != nil {
+				return nil, fmt.Errorf("object %s: %w", spec.Key, err)
+			}
+		}
+		if !obj.resolved {
+			pending = append(pending, obj)
+		}
+		members[n] = obj
+	}
+	s.opts.sortMembers(members)
+	if !s.opts.lazySizes {
+		if err := headObjects(ctx, pending, s.opts.headLimit()); err != nil {
+			return nil, err
+		}
+	}
+	return members, nil
+}
+
+// NewS3ReadSeekerFromSizes builds an S3ReadSeeker from keys and their
+// known sizes, given in the same order, without any HeadObject calls.
+func NewS3ReadSeekerFromSizes(client S3API, bucketName string, keyGroup []string, sizes []int64, opts ...Option) (rs *S3ReadSeeker, err error) {
+	specs, err := sizeSpecs(keyGroup, sizes)
+	if err != nil {
+		return nil, err
+	}
+	return NewS3ReadSeekerFromSpecs(client, bucketName, specs, opts...)
 }
 
-func NewS3ReadSeeker(client *s3.Client, bucketName string, keyGroup []string) (rs *S3ReadSeeker, err error) {
+// sizeSpecs returns the specs for keys of known sizes.
+func sizeSpecs(keyGroup []string, sizes []int64) ([]ObjectSpec, error) {
+	if len(keyGroup) != len(sizes) {
+		return nil, fmt.Errorf("got %d sizes for %d keys", len(sizes), len(keyGroup))
+	}
+	specs := make([]ObjectSpec, len(keyGroup))
+	for n, key := range keyGroup {
+		if sizes[n] < 0 {
+			return nil, fmt.Errorf("invalid size %d for object %s", sizes[n], key)
+		}
+		specs[n] = ObjectSpec{Key: key, Size: sizes[n]}
+	}
+	return specs, nil
+}
+
+func newS3ReadSeeker(client S3API, bucketName string, numMembers int, opts []Option) (rs *S3ReadSeeker, err error) {
 	rs = &S3ReadSeeker{
 		client:        client,
 		bucketName:    bucketName,
-		objectMembers: make([]*Object, len(keyGroup)),
+		objectMembers: make([]*Object, numMembers),
 		globalOffset:  0,
 	}
-	for n, key := range keyGroup {
-		headInput := &s3.HeadObjectInput{
-			Bucket: aws.String(bucketName),
-			Key:    aws.String(key),
-		}
-		result, err := client.HeadObject(context.TODO(), headInput)
+	rs.shared, err = newShared(opts)
+	if err != nil {
+		return nil, err
+	}
+	rs.ctx, rs.cancel = context.WithCancel(context.Background())
+	rs.lifetime, rs.endLifetime = context.WithCancel(context.Background())
+	return rs, nil
+}
+
+func (s *S3ReadSeeker) newObject(spec ObjectSpec) *Object {
+	obj := &Object{
+		client:     s.client,
+		bucketName: spec.Bucket,
+		key:        spec.Key,
+		versionId:  spec.VersionId,
+		start:      spec.Start,
+		shared:     s.shared,
+	}
+	obj.sseCustomerKey = s.opts.sseCustomerKey
+	obj.requesterPays = s.opts.requesterPays || spec.RequesterPays
+	if obj.bucketName == "" {
+		obj.bucketName = s.bucketName
+	}
+	if spec.Size != UnknownSize {
+		obj.size = spec.Size - spec.Start
+		obj.resolved = true
+	}
+	return obj
+}
+
+// path returns the bucket and key of o for messages.
+func (o *Object) path() string {
+	return o.bucketName + "/" + o.key
+}
+
+// headObjectInput returns the input for a HeadObject of o.
+func (o *Object) headObjectInput() *s3.HeadObjectInput {
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(o.bucketName),
+		Key:    aws.String(o.key),
+	}
+	if o.versionId != "" {
+		input.VersionId = aws.String(o.versionId)
+	}
+	if sse := o.sseCustomerKey; sse != nil {
+		input.SSECustomerAlgorithm = aws.String(sse.algorithm)
+		input.SSECustomerKey = aws.String(sse.key)
+		input.SSECustomerKeyMD5 = aws.String(sse.keyMD5)
+	}
+	if o.requesterPays {
+		input.RequestPayer = types.RequestPayerRequester
+	}
+	return input
+}
+
+// head resolves the size of o.
+func (o *Object) head(ctx context.Context) (err error) {
+	if err := o.allow(); err != nil {
+		return err
+	}
+	defer func() { o.record(ctx, err) }()
+	headInput := o.headObjectInput()
+	var result *s3.HeadObjectOutput
+	err = o.retry(ctx, func(attempt int) (err error) {
+		ctx, cancel := o.opts.requestContext(ctx)
+		defer cancel()
+		ctx, span := o.startSpan(ctx, "HeadObject", 0, 0, attempt)
+		release, err := o.acquire(ctx)
 		if err != nil {
-			return nil, err
+			endSpan(span, 0, err)
+			return err
 		}
-		rs.objectMembers[n] = &Object{
-			client:     client,
-			bucketName: bucketName,
-			key:        key,
-			size:       *result.ContentLength,
-			offset:     0,
+		defer release()
+		start := time.Now()
+		o.stats.headRequests.Add(1)
+		result, err = o.client.HeadObject(ctx, headInput, o.opts.s3Options...)
+		if err != nil && ctx.Err() != nil {
+			err = context.Cause(ctx)
 		}
+		o.reportRequest(ctx, "HeadObject", "", start, result, 0, err)
+		endSpan(span, 0, err)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("head object %s: %w", o.path(), o.apiError(err))
+	}
+	if result.ContentLength == nil {
+		return fmt.Errorf("head object %s: missing content length", o.path())
+	}
+	if archived := o.archivedError(result); archived != nil {
+		if !o.opts.allowArchived {
+			return archived
+		}
+		o.archived = archived
+	}
+	if o.start > *result.ContentLength {
+		return fmt.Errorf("head object %s: start %d beyond size %d", o.path(), o.start, *result.ContentLength)
+	}
+	o.size = *result.ContentLength - o.start
+	o.etag = aws.ToString(result.ETag)
+	o.lastModified = aws.ToTime(result.LastModified)
+	o.resolved = true
+	return nil
+}
+
+// apiError translates the errors S3 returns for a missing or forbidden
+// member into a *KeyNotFoundError, *AccessDeniedError or, when a wrong
+// SSE-C key is the likely cause, an *SSECustomerKeyError.
+func (o *Object) apiError(err error) error {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "NoSuchKey", "NoSuchVersion", "NotFound":
+			return &KeyNotFoundError{Bucket: o.bucketName, Key: o.key, VersionId: o.versionId, Err: err}
+		case "AccessDenied", "Forbidden":
+			return o.accessDeniedError(err)
+		}
+	}
+	switch errorStatus(err) {
+	case http.StatusNotFound:
+		return &KeyNotFoundError{Bucket: o.bucketName, Key: o.key, VersionId: o.versionId, Err: err}
+	case http.StatusForbidden:
+		return o.accessDeniedError(err)
+	}
+	return err
+}
+
+// accessDeniedError returns an *SSECustomerKeyError for a 403 on a request
+// that carried an SSE-C key, and an *AccessDeniedError otherwise.
+func (o *Object) accessDeniedError(err error) error {
+	if o.sseCustomerKey != nil {
+		return &SSECustomerKeyError{Bucket: o.bucketName, Key: o.key, Err: err}
+	}
+	return &AccessDeniedError{Bucket: o.bucketName, Key: o.key, Err: err}
+}
+
+// headObjects resolves the sizes of members concurrently, cancelling the
+// remaining calls on the first error.
+func headObjects(ctx context.Context, members []*Object, concurrency int) error {
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(concurrency)
+	for _, obj := range members {
+		obj := obj
+		g.Go(func() error {
+			return obj.head(gctx)
+		})
+	}
+	return g.Wait()
+}
+
+// computeSize builds ends for the leading members whose sizes are known,
+// and keyIndex.
+func (s *S3ReadSeeker) computeSize() {
+	s.ends = make([]int64, 0, len(s.objectMembers))
+	n := 0
+	for n < len(s.objectMembers) && s.objectMembers[n].resolved {
+		n++
+	}
+	s.appendEnds(s.objectMembers[:n])
+	s.keyIndex = make(map[string]int, len(s.objectMembers))
+	s.indexKeys(0)
+}
+
+// indexKeys adds the keys of the members from index first on to keyIndex.
+// The caller must hold sizeMu or have exclusive access to s.
+func (s *S3ReadSeeker) indexKeys(first int) {
+	for i := first; i < len(s.objectMembers); i++ {
+		if _, ok := s.keyIndex[s.objectMembers[i].key]; !ok {
+			s.keyIndex[s.objectMembers[i].key] = i
+		}
+	}
+}
+
+// appendEnds extends ends with members. The caller must hold sizeMu or have
+// exclusive access to s.
+func (s *S3ReadSeeker) appendEnds(members []*Object) {
+	end := totalSize(s.ends)
+	for _, obj := range members {
+		end += obj.size
+		s.ends = append(s.ends, end)
+	}
+}
+
+func totalSize(ends []int64) int64 {
+	if len(ends) == 0 {
+		return 0
+	}
+	return ends[len(ends)-1]
+}
+
+// resolvedMembers returns the members whose sizes are known and their
+// cumulative end offsets.
+func (s *S3ReadSeeker) resolvedMembers() ([]*Object, []int64) {
+	members, ends := s.members()
+	return members[:len(ends)], ends
+}
+
+// members returns all members and the cumulative end offsets of the
+// leading ones whose sizes are known.
+func (s *S3ReadSeeker) members() ([]*Object, []int64) {
+	s.sizeMu.Lock()
+	defer s.sizeMu.Unlock()
+	return s.objectMembers, s.ends
+}
+
+// resolveUntil resolves member sizes in order until the resolved members
+// cover end bytes or every member is resolved.
+func (s *S3ReadSeeker) resolveUntil(ctx context.Context, end int64) error {
+	if members, ends := s.members(); len(ends) == len(members) || totalSize(ends) >= end {
+		return nil
+	}
+	s.resolveMu.Lock()
+	defer s.resolveMu.Unlock()
+	for {
+		members, ends := s.members()
+		if len(ends) == len(members) || totalSize(ends) >= end {
+			return nil
+		}
+		obj := members[len(ends)]
+		if !obj.resolved {
+			if err := obj.head(ctx); err != nil {
+				return err
+			}
+		}
+		s.sizeMu.Lock()
+		s.appendEnds([]*Object{obj})
+		s.sizeMu.Unlock()
+	}
+}
+
+// resolveAll resolves the sizes of all remaining members.
+func (s *S3ReadSeeker) resolveAll(ctx context.Context) error {
+	if members, ends := s.members(); len(ends) == len(members) {
+		return nil
+	}
+	s.resolveMu.Lock()
+	defer s.resolveMu.Unlock()
+	members, ends := s.members()
+	remaining := members[len(ends):]
+	var pending []*Object
+	for _, obj := range remaining {
+		if !obj.resolved {
+			pending = append(pending, obj)
+		}
+	}
+	if err := headObjects(ctx, pending, s.opts.headLimit()); err != nil {
+		return err
+	}
+	s.sizeMu.Lock()
+	defer s.sizeMu.Unlock()
+	s.appendEnds(remaining)
+	return nil
+}
+
+// Size returns the total length of the concatenated objects. Unlike
+// Seek(0, io.SeekEnd) it does not move the current offset.
+//
+// With WithLazySizes, Size resolves all remaining member sizes. If that
+// fails it returns -1; the error is reported by the next Seek or Read
+// that needs the failing member.
+func (s *S3ReadSeeker) Size() int64 {
+	if err := s.resolveAll(s.context()); err != nil {
+		return -1
+	}
+	_, ends := s.resolvedMembers()
+	return totalSize(ends)
+}
+
+// Len returns the number of bytes between the current offset and the end
+// of the stream, like bytes.Reader.Len. Use Size for the total length.
+func (s *S3ReadSeeker) Len() int64 {
+	size := s.Size()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if size < 0 || s.globalOffset >= size {
+		return 0
+	}
+	return size - s.globalOffset
+}
+
+// Offset returns the current offset, like Seek(0, io.SeekCurrent) without
+// resolving any sizes.
+func (s *S3ReadSeeker) Offset() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.globalOffset
+}
+
+// Keys returns the keys of the members in the order they are
+// concatenated, e.g. as listed by NewS3ReadSeekerFromPrefix.
+func (s *S3ReadSeeker) Keys() []string {
+	members, _ := s.members()
+	keys := make([]string, len(members))
+	for i, obj := range members {
+		keys[i] = obj.key
+	}
+	return keys
+}
+
+// SetContext sets the context used by Read and ReadAt for their S3
+// requests, so that cancelling ctx aborts any in-flight GetObject.
+// Requests still running under the previous context are cancelled.
+func (s *S3ReadSeeker) SetContext(ctx context.Context) {
+	s.ctxMu.Lock()
+	defer s.ctxMu.Unlock()
+	s.cancel()
+	s.ctx, s.cancel = context.WithCancel(ctx)
+}
+
+func (s *S3ReadSeeker) context() context.Context {
+	s.ctxMu.RLock()
+	defer s.ctxMu.RUnlock()
+	return s.ctx
+}
+
+// bind returns ctx, also canceled with the cause ErrClosed once s is
+// closed, for a call running with the caller's context. The returned func
+// must be called once the call is done.
+func (s *S3ReadSeeker) bind(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	stop := context.AfterFunc(s.lifetime, func() { cancel(ErrClosed) })
+	return ctx, func() {
+		stop()
+		cancel(nil)
 	}
-	return rs, nil
 }
 
 func (s *S3ReadSeeker) Read(p []byte) (n int, err error) {
+	return s.ReadContext(s.context(), p)
+}
+
+// ReadContext is like Read but issues any S3 requests with ctx.
+func (s *S3ReadSeeker) ReadContext(ctx context.Context, p []byte) (n int, err error) {
+	return s.read(ctx, p, s.opts.readAheadSize)
+}
+
+// read reads into p from the current offset, through a read-ahead buffer
+// of bufferSize bytes if it is positive.
+func (s *S3ReadSeeker) read(ctx context.Context, p []byte, bufferSize int) (n int, err error) {
+	if s.closed.Load() {
+		return 0, ErrClosed
+	}
+	if !s.opts.streaming {
+		// readStream binds the body it keeps open for as long as it is
+		var stop context.CancelFunc
+		ctx, stop = s.bind(ctx)
+		defer stop()
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	n, err = s.ReadAt(p, s.globalOffset)
-	if err != nil {
-		return n, err
+	switch {
+	case s.opts.streaming:
+		n, err = s.readStream(ctx, p)
+	case bufferSize > 0:
+		n, err = s.readBuffered(ctx, p, bufferSize)
+	default:
+		n, err = s.readAt(ctx, p, s.globalOffset)
 	}
+	s.stats.bytesReturned.Add(int64(n))
 	s.globalOffset += int64(n)
+	if err == io.EOF && n > 0 {
+		// like os.File, report io.EOF on the next Read
+		err = nil
+	}
 	return n, err
 }
 
+// readByteBufferSize is the read-ahead buffer ReadByte uses without
+// WithReadAheadSize or WithReadAhead.
+const readByteBufferSize = 4 << 10
+
+// ReadByte implements io.ByteReader. It is served from the read-ahead
+// buffer of WithReadAheadSize or WithReadAhead, or the open body of
+// WithStreaming, and otherwise from a buffer of 4 KiB, so that reading a
+// byte at a time does not issue a GetObject per byte.
+func (s *S3ReadSeeker) ReadByte() (byte, error) {
+	bufferSize := s.opts.readAheadSize
+	if bufferSize == 0 {
+		bufferSize = readByteBufferSize
+	}
+	var b [1]byte
+	if _, err := s.read(s.context(), b[:], bufferSize); err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+// ReadAt implements io.ReaderAt. It reads from the stored context and does
+// not use or move the current offset. It is safe for concurrent use.
 func (s *S3ReadSeeker) ReadAt(p []byte, off int64) (n int, err error) {
+	return s.ReadAtContext(s.context(), p, off)
+}
+
+// ReadAtContext is like ReadAt but issues any S3 requests with ctx.
+func (s *S3ReadSeeker) ReadAtContext(ctx context.Context, p []byte, off int64) (n int, err error) {
+	ctx, stop := s.bind(ctx)
+	defer stop()
+	n, err = s.readAt(ctx, p, off)
+	s.stats.bytesReturned.Add(int64(n))
+	return n, err
+}
+
+func (s *S3ReadSeeker) readAt(ctx context.Context, p []byte, off int64) (n int, err error) {
+	if s.closed.Load() {
+		return 0, ErrClosed
+	}
+	if off < 0 {
+		return 0, fmt.Errorf("%w: %d", ErrNegativeOffset, off)
+	}
+	if len(p) == 0 {
+		// no request is needed, only check whether off is at the end
+		if err := s.resolveUntil(ctx, off+1); err != nil {
+			return 0, err
+		}
+		if _, ends := s.resolvedMembers(); off >= totalSize(ends) {
+			return 0, io.EOF
+		}
+		return 0, nil
+	}
+	if err := s.resolveUntil(ctx, off+int64(len(p))); err != nil {
+		return 0, err
+	}
+	members, ends := s.resolvedMembers()
+	parts := planReads(members, ends, p, off)
+	if s.opts.readConcurrency > 1 && len(parts) > 1 {
+		n, err = readPartsConcurrently(ctx, parts, s.opts.readConcurrency)
+	} else {
+		n, err = readParts(ctx, parts)
+	}
+	if err == nil && n < len(p) {
+		// the stream ended before p was filled
+		err = io.EOF
+	}
+	return n, err
+}
+
+// memberRead is the part of a ReadAt served by a single member.
+type memberRead struct {
+	obj *Object
+	p   []byte
+	off int64
+	// global is the offset of the read in the concatenated stream
+	global int64
+}
+
+// planReads splits a read of p at the global offset off into consecutive
+// per-member reads. The plan stops early if the members end before p is
+// filled.
+func planReads(members []*Object, ends []int64, p []byte, off int64) []memberRead {
+	var parts []memberRead
+	// find the first member ending after off
+	i := sort.Search(len(ends), func(i int) bool { return ends[i] > off })
 	var pOff int64
-	for _, obj := range s.objectMembers {
-		if off >= obj.size {
-			// offset exceedes the object size
-			// skip it and rewind the offset
-			off = off - obj.size
+	for ; i < len(members) && pOff < int64(len(p)); i++ {
+		obj := members[i]
+		// local is the offset within the object, skipping empty objects
+		local := off + pOff - (ends[i] - obj.size)
+		if local >= obj.size {
 			continue
 		}
-		// end is s3 range end, it's closed interval
-		end := off + int64(len(p[pOff:])) - 1
-		// if end exceeds the object size, we need to read from the end of the object
-		if end+1 > obj.size {
-			newPOff := pOff + (obj.size - off)
-			m, err := obj.ReadAt(p[pOff:newPOff], off)
-			if err != nil {
-				return n, err
-			}
-			pOff = newPOff
-			n += m
-			off = 0
+		m := min(int64(len(p))-pOff, obj.size-local)
+		parts = append(parts, memberRead{obj: obj, p: p[pOff : pOff+m], off: local, global: off + pOff})
+		pOff += m
+	}
+	return parts
+}
+
+// read reads the part, naming the global offset in any error.
+func (part memberRead) read(ctx context.Context) (int, error) {
+	n, err := part.obj.ReadAtContext(ctx, part.p, part.off)
+	if err != nil {
+		err = fmt.Errorf("read at offset %d: %w", part.global+int64(n), err)
+	}
+	return n, err
+}
+
+// readParts reads parts one after another.
+func readParts(ctx context.Context, parts []memberRead) (n int, err error) {
+	for _, part := range parts {
+		m, err := part.read(ctx)
+		n += m
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// readPartsConcurrently reads up to concurrency parts at a time. It returns
+// the first error that occurred and the number of contiguous bytes read
+// from the start of the first part.
+func readPartsConcurrently(ctx context.Context, parts []memberRead, concurrency int) (n int, err error) {
+	counts := make([]int, len(parts))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(concurrency)
+	for i, part := range parts {
+		i, part := i, part
+		g.Go(func() (err error) {
+			counts[i], err = part.read(gctx)
+			return err
+		})
+	}
+	err = g.Wait()
+	for i, part := range parts {
+		n += counts[i]
+		if counts[i] < len(part.p) {
+			break
+		}
+	}
+	return n, err
+}
+
+// WriteTo implements io.WriterTo. Starting at the current offset it streams
+// each remaining member with a single GetObject, so that io.Copy does not
+// issue one ranged request per copy buffer. Opening each GetObject is
+// retried as configured by WithRetryConfig. The offset is advanced by the
+// bytes written, also when an error is returned.
+func (s *S3ReadSeeker) WriteTo(w io.Writer) (n int64, err error) {
+	if s.closed.Load() {
+		return 0, ErrClosed
+	}
+	ctx := s.context()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.resolveAll(ctx); err != nil {
+		return 0, err
+	}
+	members, ends := s.resolvedMembers()
+	i := sort.Search(len(ends), func(i int) bool { return ends[i] > s.globalOffset })
+	for ; i < len(members); i++ {
+		obj := members[i]
+		off := s.globalOffset - (ends[i] - obj.size)
+		if off >= obj.size {
 			continue
 		}
-		// read last part
-		m, err := obj.ReadAt(p[pOff:], off)
+		m, err := obj.writeTo(ctx, w, off)
+		n += m
+		s.stats.bytesReturned.Add(m)
+		s.globalOffset += m
 		if err != nil {
 			return n, err
 		}
-		n += m
-		return n, nil
 	}
-	return 0, io.EOF
+	return n, nil
 }
 
+// Seek implements io.Seeker. Seeking to the end of the stream is allowed,
+// while an offset past it fails with ErrOffsetOutOfRange and leaves the
+// current offset unchanged. With WithLazySizes the members up to the new
+// offset are resolved first.
 func (s *S3ReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	if s.closed.Load() {
+		return 0, ErrClosed
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -125,16 +1053,53 @@ func (s *S3ReadSeeker) Seek(offset int64, whence int) (int64, error) {
 	case io.SeekCurrent:
 		newOffset = s.globalOffset + offset
 	case io.SeekEnd:
-		for _, obj := range s.objectMembers {
-			newOffset += obj.size
+		if err := s.resolveAll(s.context()); err != nil {
+			return 0, err
 		}
-		newOffset += offset
+		_, ends := s.resolvedMembers()
+		newOffset = totalSize(ends) + offset
 	default:
 		return 0, fmt.Errorf("invalid whence: %d", whence)
 	}
 	if newOffset < 0 {
-		return 0, fmt.Errorf("invalid offset: %d", newOffset)
+		return 0, fmt.Errorf("%w: %d", ErrNegativeOffset, newOffset)
+	}
+	// with lazy sizes, resolve the members seeked past
+	if err := s.resolveUntil(s.context(), newOffset); err != nil {
+		return 0, err
+	}
+	if _, ends := s.resolvedMembers(); newOffset > totalSize(ends) {
+		return 0, fmt.Errorf("%w: %d beyond %d bytes", ErrOffsetOutOfRange, newOffset, totalSize(ends))
+	}
+	if newOffset != s.globalOffset {
+		s.closeStream()
+	}
+	if !s.bufferContains(newOffset) {
+		s.buf = s.buf[:0]
+		if len(s.prefetches) > 0 && s.prefetches[0].off != newOffset {
+			s.stopPrefetch()
+		}
 	}
 	s.globalOffset = newOffset
 	return s.globalOffset, nil
 }
+
+// Close cancels the stored context and the contexts passed to calls in
+// progress, aborting any in-flight Read, ReadAt, WriteTo, Bytes,
+// DownloadTo or RangeReader, releases the body kept open by WithStreaming
+// and makes subsequent reads and seeks return ErrClosed. It is safe to
+// call Close more than once and concurrently with reads.
+func (s *S3ReadSeeker) Close() error {
+	if s.closed.Swap(true) {
+		return nil
+	}
+	s.ctxMu.Lock()
+	s.cancel()
+	s.ctxMu.Unlock()
+	s.endLifetime()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closeStream()
+	return nil
+}
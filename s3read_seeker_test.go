@@ -0,0 +1,839 @@
+package s3ReadSeeker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+const testBucket = "bucket"
+
+// fakeKey locates an object in fakeS3.
+type fakeKey struct {
+	bucket, key string
+}
+
+type fakeObject struct {
+	data         []byte
+	version      int
+	etag         string
+	lastModified time.Time
+}
+
+// fakeS3 is an in-memory S3API holding any number of buckets. It serves
+// ranged GetObjects the way S3 does, honors If-Match and
+// If-Unmodified-Since, and records every request it receives.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[fakeKey]*fakeObject
+	gets    []*s3.GetObjectInput
+	heads   []*s3.HeadObjectInput
+	lists   []*s3.ListObjectsV2Input
+	// onGet, if set, is called with each GetObject before it is served,
+	// e.g. to delay it, and fails it if it returns an error
+	onGet func(ctx context.Context, in *s3.GetObjectInput) error
+	// onHead is like onGet for HeadObject
+	onHead func(ctx context.Context, in *s3.HeadObjectInput) error
+	// wrapBody, if set, replaces the body of n bytes of each GetObject,
+	// e.g. to cut it short. It is called with mu held.
+	wrapBody func(in *s3.GetObjectInput, body io.Reader, n int64) io.Reader
+	// ignoreRange makes GetObject answer like stores that ignore a Range
+	// header, with the whole object and no Content-Range
+	ignoreRange bool
+}
+
+var _ S3API = (*fakeS3)(nil)
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{objects: make(map[fakeKey]*fakeObject)}
+}
+
+// put stores data under key in testBucket, see putIn.
+func (f *fakeS3) put(key string, data []byte) {
+	f.putIn(testBucket, key, data)
+}
+
+// putIn stores data under key in bucket, replacing any previous object
+// with a new ETag and modification time.
+func (f *fakeS3) putIn(bucket, key string, data []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	version := 1
+	if obj, ok := f.objects[fakeKey{bucket, key}]; ok {
+		version = obj.version + 1
+	}
+	f.objects[fakeKey{bucket, key}] = &fakeObject{
+		data:         data,
+		version:      version,
+		etag:         fmt.Sprintf(`"%s-%d"`, key, version),
+		lastModified: time.Date(2024, 1, 1, 0, 0, version, 0, time.UTC),
+	}
+}
+
+// object returns the object stored under key in bucket, or a NoSuchKey
+// error.
+func (f *fakeS3) object(bucket, key string) (*fakeObject, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	obj, ok := f.objects[fakeKey{bucket, key}]
+	if !ok {
+		return nil, &smithy.GenericAPIError{Code: "NoSuchKey", Message: bucket + "/" + key}
+	}
+	return obj, nil
+}
+
+func (f *fakeS3) GetObject(ctx context.Context, in *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	f.mu.Lock()
+	f.gets = append(f.gets, in)
+	onGet := f.onGet
+	f.mu.Unlock()
+	if onGet != nil {
+		if err := onGet(ctx, in); err != nil {
+			return nil, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	obj, err := f.object(aws.ToString(in.Bucket), aws.ToString(in.Key))
+	if err != nil {
+		return nil, err
+	}
+	if in.IfMatch != nil && *in.IfMatch != obj.etag ||
+		in.IfUnmodifiedSince != nil && obj.lastModified.After(*in.IfUnmodifiedSince) {
+		return nil, &smithy.GenericAPIError{Code: "PreconditionFailed"}
+	}
+	size := int64(len(obj.data))
+	start, end := int64(0), size-1
+	f.mu.Lock()
+	ranged := in.Range != nil && !f.ignoreRange
+	f.mu.Unlock()
+	if ranged {
+		if _, err := fmt.Sscanf(*in.Range, "bytes=%d-%d", &start, &end); err != nil {
+			end = size - 1
+			if _, err := fmt.Sscanf(*in.Range, "bytes=%d-", &start); err != nil {
+				return nil, &smithy.GenericAPIError{Code: "InvalidArgument", Message: *in.Range}
+			}
+		}
+		if start >= size {
+			return nil, &smithy.GenericAPIError{Code: "InvalidRange", Message: *in.Range}
+		}
+		end = min(end, size-1)
+	}
+	var body io.Reader = bytes.NewReader(obj.data[start : end+1])
+	f.mu.Lock()
+	if f.wrapBody != nil {
+		body = f.wrapBody(in, body, end-start+1)
+	}
+	f.mu.Unlock()
+	out := &s3.GetObjectOutput{
+		Body:          io.NopCloser(body),
+		ContentLength: aws.Int64(end - start + 1),
+		ETag:          aws.String(obj.etag),
+		LastModified:  aws.Time(obj.lastModified),
+	}
+	if ranged {
+		out.ContentRange = aws.String(fmt.Sprintf("bytes %d-%d/%d", start, end, size))
+	}
+	return out, nil
+}
+
+func (f *fakeS3) HeadObject(ctx context.Context, in *s3.HeadObjectInput, _ ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	f.mu.Lock()
+	f.heads = append(f.heads, in)
+	onHead := f.onHead
+	f.mu.Unlock()
+	if onHead != nil {
+		if err := onHead(ctx, in); err != nil {
+			return nil, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	obj, err := f.object(aws.ToString(in.Bucket), aws.ToString(in.Key))
+	if err != nil {
+		return nil, err
+	}
+	return &s3.HeadObjectOutput{
+		ContentLength: aws.Int64(int64(len(obj.data))),
+		ETag:          aws.String(obj.etag),
+		LastModified:  aws.Time(obj.lastModified),
+	}, nil
+}
+
+// getCount returns the number of GetObjects received so far.
+func (f *fakeS3) getCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.gets)
+}
+
+// headCount returns the number of HeadObjects received so far.
+func (f *fakeS3) headCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.heads)
+}
+
+// fakeMembers stores an object of each of sizes, filled with a pattern
+// that differs between members and offsets, and returns their keys and
+// their concatenation.
+func fakeMembers(f *fakeS3, sizes ...int) (keys []string, all []byte) {
+	for i, size := range sizes {
+		key := fmt.Sprintf("part-%05d", i)
+		data := make([]byte, size)
+		for j := range data {
+			data[j] = byte(i*31 + j*7 + j>>8)
+		}
+		f.put(key, data)
+		keys = append(keys, key)
+		all = append(all, data...)
+	}
+	return keys, all
+}
+
+func TestReadSeekerOverFakeClient(t *testing.T) {
+	f := newFakeS3()
+	keys, all := fakeMembers(f, 1000, 37, 4096, 5)
+	rs, err := NewS3ReadSeeker(f, testBucket, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	if got := f.headCount(); got != len(keys) {
+		t.Errorf("HeadObjects = %d, want %d", got, len(keys))
+	}
+	if got := rs.Size(); got != int64(len(all)) {
+		t.Errorf("Size() = %d, want %d", got, len(all))
+	}
+	// TestReader exercises Read, ReadAt and Seek against the expected
+	// content, including reads spanning members
+	if err := iotest.TestReader(rs, all); err != nil {
+		t.Fatal(err)
+	}
+	for _, in := range f.gets {
+		if aws.ToString(in.Bucket) != testBucket || in.Range == nil {
+			t.Fatalf("GetObject of %s/%s with range %q, want a ranged GetObject in %s",
+				aws.ToString(in.Bucket), aws.ToString(in.Key), aws.ToString(in.Range), testBucket)
+		}
+	}
+}
+
+func TestReadAtMemberBoundaries(t *testing.T) {
+	f := newFakeS3()
+	keys, all := fakeMembers(f, 10, 1, 7, 10, 3)
+	rs, err := NewS3ReadSeeker(f, testBucket, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	_, ends := rs.members()
+	for _, end := range ends[:len(ends)-1] {
+		for _, tc := range []struct {
+			name string
+			off  int64
+			n    int
+		}{
+			{"starting at", end, 2},
+			{"ending at", end - 2, 2},
+			{"straddling", end - 1, 2},
+			{"single byte at", end, 1},
+		} {
+			p := make([]byte, tc.n)
+			n, err := rs.ReadAt(p, tc.off)
+			if err != nil || n != tc.n {
+				t.Fatalf("%s boundary %d: ReadAt(%d bytes, %d) = %d, %v", tc.name, end, tc.n, tc.off, n, err)
+			}
+			if want := all[tc.off : tc.off+int64(tc.n)]; !bytes.Equal(p, want) {
+				t.Fatalf("%s boundary %d: read %v, want %v", tc.name, end, p, want)
+			}
+		}
+	}
+}
+
+func TestSeekToMemberBoundaries(t *testing.T) {
+	f := newFakeS3()
+	keys, all := fakeMembers(f, 10, 1, 7, 10, 3)
+	rs, err := NewS3ReadSeeker(f, testBucket, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	_, ends := rs.members()
+	for _, end := range ends {
+		if _, err := rs.Seek(end, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		rest, err := io.ReadAll(rs)
+		if err != nil {
+			t.Fatalf("ReadAll from %d: %v", end, err)
+		}
+		if !bytes.Equal(rest, all[end:]) {
+			t.Fatalf("ReadAll from %d read %d bytes, want %d", end, len(rest), len(all)-int(end))
+		}
+	}
+}
+
+// BenchmarkReadAtManyMembers reads at random offsets of a stream of 10k
+// members, which has to find the member of each offset.
+func BenchmarkReadAtManyMembers(b *testing.B) {
+	const members = 10000
+	f := newFakeS3()
+	sizes := make([]int, members)
+	for i := range sizes {
+		sizes[i] = 64 + i%64
+	}
+	keys, all := fakeMembers(f, sizes...)
+	int64Sizes := make([]int64, members)
+	for i, size := range sizes {
+		int64Sizes[i] = int64(size)
+	}
+	rs, err := NewS3ReadSeekerFromSizes(f, testBucket, keys, int64Sizes)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer rs.Close()
+	rng := rand.New(rand.NewSource(1))
+	p := make([]byte, 16)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		off := rng.Int63n(int64(len(all) - len(p)))
+		if _, err := rs.ReadAt(p, off); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkSeekEndManyMembers seeks to the end of a stream of 10k
+// members, whose total size is known once resolved.
+func BenchmarkSeekEndManyMembers(b *testing.B) {
+	const members = 10000
+	keys := make([]string, members)
+	sizes := make([]int64, members)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("part-%05d", i)
+		sizes[i] = 1 << 20
+	}
+	rs, err := NewS3ReadSeekerFromSizes(newFakeS3(), testBucket, keys, sizes)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer rs.Close()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := rs.Seek(0, io.SeekEnd); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// sleep waits for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestReadAtFetchesMembersConcurrently(t *testing.T) {
+	const delay = 200 * time.Millisecond
+	f := newFakeS3()
+	keys, all := fakeMembers(f, 100, 200, 300, 400, 500)
+	f.onGet = func(ctx context.Context, _ *s3.GetObjectInput) error {
+		return sleep(ctx, delay)
+	}
+	rs, err := NewS3ReadSeeker(f, testBucket, keys, WithMaxConcurrency(len(keys)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	// from within the first member to within the last
+	off := int64(50)
+	p := make([]byte, len(all)-100)
+	start := time.Now()
+	n, err := rs.ReadAt(p, off)
+	elapsed := time.Since(start)
+	if err != nil || n != len(p) {
+		t.Fatalf("ReadAt = %d, %v, want %d", n, err, len(p))
+	}
+	if !bytes.Equal(p, all[off:off+int64(len(p))]) {
+		t.Fatal("ReadAt placed bytes wrongly")
+	}
+	if got := f.getCount(); got != len(keys) {
+		t.Errorf("GetObjects = %d, want %d", got, len(keys))
+	}
+	// sequential fetches would take len(keys)*delay
+	if elapsed >= 2*delay {
+		t.Errorf("ReadAt of %d members served in %s each took %s", len(keys), delay, elapsed)
+	}
+}
+
+func TestZeroByteMembers(t *testing.T) {
+	f := newFakeS3()
+	keys, all := fakeMembers(f, 0, 10, 0, 0, 5, 0, 7, 0)
+	rs, err := NewS3ReadSeeker(f, testBucket, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	if err := iotest.TestReader(rs, all); err != nil {
+		t.Fatal(err)
+	}
+	// 10 and 15 are where the empty members lie
+	for _, off := range []int64{0, 10, 15} {
+		if _, err := rs.Seek(off, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		p := make([]byte, 3)
+		n, err := rs.Read(p)
+		if err != nil || !bytes.Equal(p[:n], all[off:off+3]) {
+			t.Fatalf("Read after Seek to %d = %v, %v, want %v", off, p[:n], err, all[off:off+3])
+		}
+		n, err = rs.ReadAt(p, off)
+		if err != nil || !bytes.Equal(p[:n], all[off:off+3]) {
+			t.Fatalf("ReadAt(%d) = %v, %v, want %v", off, p[:n], err, all[off:off+3])
+		}
+	}
+	if _, err := rs.Seek(0, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := rs.Read(make([]byte, 1)); n != 0 || err != io.EOF {
+		t.Fatalf("Read at the end = %d, %v, want io.EOF", n, err)
+	}
+	empty := map[string]bool{keys[0]: true, keys[2]: true, keys[3]: true, keys[5]: true, keys[7]: true}
+	for _, in := range f.gets {
+		if key := aws.ToString(in.Key); empty[key] {
+			t.Errorf("GetObject of the empty member %s", key)
+		}
+	}
+}
+
+func TestOnlyZeroByteMembers(t *testing.T) {
+	f := newFakeS3()
+	keys, _ := fakeMembers(f, 0, 0, 0)
+	rs, err := NewS3ReadSeeker(f, testBucket, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	if got := rs.Size(); got != 0 {
+		t.Errorf("Size() = %d, want 0", got)
+	}
+	if n, err := rs.Read(make([]byte, 8)); n != 0 || err != io.EOF {
+		t.Errorf("Read = %d, %v, want io.EOF", n, err)
+	}
+	if got := f.getCount(); got != 0 {
+		t.Errorf("GetObjects = %d, want 0", got)
+	}
+}
+
+func TestHeadObjectsConcurrently(t *testing.T) {
+	const limit = 4
+	f := newFakeS3()
+	sizes := make([]int, 40)
+	for i := range sizes {
+		sizes[i] = i + 1
+	}
+	keys, all := fakeMembers(f, sizes...)
+	var mu sync.Mutex
+	running, peak := 0, 0
+	f.onHead = func(ctx context.Context, in *s3.HeadObjectInput) error {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+		// let later members complete first
+		var i int
+		fmt.Sscanf(aws.ToString(in.Key), "part-%d", &i)
+		return sleep(ctx, time.Duration(len(keys)-i)*time.Millisecond)
+	}
+	rs, err := NewS3ReadSeeker(f, testBucket, keys, WithHeadConcurrency(limit))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	if peak != limit {
+		t.Errorf("%d HeadObjects ran concurrently, want %d", peak, limit)
+	}
+	if got := rs.Keys(); !slices.Equal(got, keys) {
+		t.Errorf("Keys() = %v, want %v", got, keys)
+	}
+	got, err := io.ReadAll(rs)
+	if err != nil || !bytes.Equal(got, all) {
+		t.Fatalf("ReadAll = %d bytes, %v, want %d bytes", len(got), err, len(all))
+	}
+}
+
+func TestHeadObjectsFailure(t *testing.T) {
+	f := newFakeS3()
+	keys, _ := fakeMembers(f, 1, 2, 3, 4, 5, 6, 7, 8)
+	keys[3] = "missing"
+	f.onHead = func(ctx context.Context, in *s3.HeadObjectInput) error {
+		if aws.ToString(in.Key) == "missing" {
+			return nil
+		}
+		// the failure cancels the HeadObjects still waiting
+		return sleep(ctx, time.Minute)
+	}
+	_, err := NewS3ReadSeeker(f, testBucket, keys, WithHeadConcurrency(len(keys)))
+	if !errors.Is(err, ErrKeyNotFound) || !strings.Contains(err.Error(), "missing") {
+		t.Fatalf("NewS3ReadSeeker() error = %v, want ErrKeyNotFound naming the key", err)
+	}
+}
+
+func TestReadAtEndOfStream(t *testing.T) {
+	f := newFakeS3()
+	keys, all := fakeMembers(f, 10, 20, 30)
+	rs, err := NewS3ReadSeeker(f, testBucket, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	size := int64(len(all))
+	for _, tc := range []struct {
+		name    string
+		off     int64
+		n       int
+		wantN   int
+		wantErr error
+	}{
+		{"entirely past the end", size + 5, 10, 0, io.EOF},
+		{"at the end", size, 10, 0, io.EOF},
+		{"straddling the end", size - 4, 10, 4, io.EOF},
+		{"ending exactly at the end", size - 10, 10, 10, nil},
+		{"spanning the last two members past the end", size - 35, 40, 35, io.EOF},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := make([]byte, tc.n)
+			n, err := rs.ReadAt(p, tc.off)
+			if n != tc.wantN || err != tc.wantErr && !(tc.wantErr == nil && err == io.EOF) {
+				t.Fatalf("ReadAt(%d bytes, %d) = %d, %v, want %d, %v", tc.n, tc.off, n, err, tc.wantN, tc.wantErr)
+			}
+			if tc.wantN > 0 && !bytes.Equal(p[:n], all[tc.off:]) {
+				t.Fatalf("ReadAt read %v, want %v", p[:n], all[tc.off:])
+			}
+		})
+	}
+}
+
+func TestZeroLengthReads(t *testing.T) {
+	f := newFakeS3()
+	keys, all := fakeMembers(f, 10, 20)
+	rs, err := NewS3ReadSeeker(f, testBucket, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	members, _ := rs.members()
+	size := int64(len(all))
+	for _, tc := range []struct {
+		name    string
+		off     int64
+		wantErr error
+	}{
+		{"at the start", 0, nil},
+		{"mid-stream", 15, nil},
+		{"at the end", size, io.EOF},
+		{"past the end", size + 10, io.EOF},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if n, err := rs.ReadAt([]byte{}, tc.off); n != 0 || err != tc.wantErr {
+				t.Errorf("ReadAt(0 bytes, %d) = %d, %v, want 0, %v", tc.off, n, err, tc.wantErr)
+			}
+			// the same offsets relative to the last member
+			obj := members[len(members)-1]
+			off := tc.off - (size - obj.size)
+			if n, err := obj.ReadAt(nil, max(off, 0)); n != 0 || err != tc.wantErr {
+				t.Errorf("Object.ReadAt(0 bytes, %d) = %d, %v, want 0, %v", max(off, 0), n, err, tc.wantErr)
+			}
+		})
+	}
+	if got := f.getCount(); got != 0 {
+		t.Errorf("zero-length reads issued %d GetObjects", got)
+	}
+}
+
+func TestNegativeOffsets(t *testing.T) {
+	f := newFakeS3()
+	keys, _ := fakeMembers(f, 10, 20)
+	rs, err := NewS3ReadSeeker(f, testBucket, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	members, _ := rs.members()
+	p := make([]byte, 5)
+	for _, tc := range []struct {
+		name string
+		op   func() error
+	}{
+		{"ReadAt", func() error { _, err := rs.ReadAt(p, -5); return err }},
+		{"Object.ReadAt", func() error { _, err := members[1].ReadAt(p, -1); return err }},
+		{"Seek from the start", func() error { _, err := rs.Seek(-1, io.SeekStart); return err }},
+		{"Seek from the current offset", func() error { _, err := rs.Seek(-1, io.SeekCurrent); return err }},
+		{"Seek from the end", func() error { _, err := rs.Seek(-31, io.SeekEnd); return err }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.op(); !errors.Is(err, ErrNegativeOffset) {
+				t.Errorf("error = %v, want ErrNegativeOffset", err)
+			}
+		})
+	}
+	if got := f.getCount(); got != 0 {
+		t.Errorf("negative offsets issued %d GetObjects", got)
+	}
+	if off, _ := rs.Seek(0, io.SeekCurrent); off != 0 {
+		t.Errorf("failed Seeks moved the offset to %d", off)
+	}
+}
+
+func TestReadAtResumesTruncatedBodies(t *testing.T) {
+	f := newFakeS3()
+	keys, all := fakeMembers(f, 64<<10, 100, 32<<10)
+	rng := rand.New(rand.NewSource(1))
+	// truncations is the number of bodies still to be cut short
+	truncations := 0
+	f.wrapBody = func(_ *s3.GetObjectInput, body io.Reader, n int64) io.Reader {
+		if truncations == 0 || n < 2 {
+			return body
+		}
+		truncations--
+		// drop the connection after a random part of the body
+		return io.MultiReader(io.LimitReader(body, 1+rng.Int63n(n-1)), iotest.ErrReader(io.ErrUnexpectedEOF))
+	}
+	rs, err := NewS3ReadSeeker(f, testBucket, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	for i := 0; i < 200; i++ {
+		off := rng.Int63n(int64(len(all)))
+		p := make([]byte, 2+rng.Int63n(int64(len(all))-off))
+		if int64(len(p)) > int64(len(all))-off {
+			p = p[:int64(len(all))-off]
+		}
+		f.mu.Lock()
+		truncations = rng.Intn(maxResumes + 1)
+		f.mu.Unlock()
+		n, err := rs.ReadAt(p, off)
+		if err != nil && err != io.EOF || n != len(p) {
+			t.Fatalf("ReadAt(%d bytes, %d) = %d, %v", len(p), off, n, err)
+		}
+		if !bytes.Equal(p, all[off:off+int64(n)]) {
+			t.Fatalf("ReadAt(%d bytes, %d) read wrong bytes", len(p), off)
+		}
+	}
+
+	// a body cut short more often than resumed is an error
+	f.mu.Lock()
+	truncations = maxResumes + 1
+	f.mu.Unlock()
+	gets := f.getCount()
+	p := make([]byte, 1000)
+	if n, err := rs.ReadAt(p, 0); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("ReadAt of a body truncated %d times = %d, %v, want io.ErrUnexpectedEOF", maxResumes+1, n, err)
+	}
+	if got := f.getCount() - gets; got != maxResumes+1 {
+		t.Errorf("GetObjects = %d, want %d", got, maxResumes+1)
+	}
+}
+
+func TestCloseAbortsCallsWithTheirOwnContext(t *testing.T) {
+	f := newFakeS3()
+	keys, _ := fakeMembers(f, 100, 100)
+	started := make(chan struct{}, 8)
+	f.onGet = func(ctx context.Context, _ *s3.GetObjectInput) error {
+		started <- struct{}{}
+		return sleep(ctx, time.Minute)
+	}
+	rs, err := NewS3ReadSeeker(f, testBucket, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	calls := map[string]func() error{
+		"ReadContext": func() error {
+			_, err := rs.ReadContext(ctx, make([]byte, 10))
+			return err
+		},
+		"ReadAtContext": func() error {
+			_, err := rs.ReadAtContext(ctx, make([]byte, 10), 50)
+			return err
+		},
+		"Bytes": func() error {
+			_, err := rs.Bytes(ctx, 0)
+			return err
+		},
+		"DownloadTo": func() error {
+			_, err := rs.DownloadTo(ctx, io.Discard, 2)
+			return err
+		},
+		"RangeReader": func() error {
+			r, err := rs.RangeReader(ctx, 0, 200)
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+			_, err = io.ReadAll(r)
+			return err
+		},
+	}
+	errs := make(chan error, len(calls))
+	for name, call := range calls {
+		name, call := name, call
+		go func() { errs <- fmt.Errorf("%s: %w", name, call()) }()
+		<-started
+	}
+	closed := make(chan error)
+	go func() { closed <- rs.Close() }()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Close blocked behind the calls in progress")
+	}
+	for range calls {
+		select {
+		case err := <-errs:
+			if !errors.Is(err, ErrClosed) {
+				t.Errorf("error = %v, want ErrClosed", err)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("Close did not abort a call in progress")
+		}
+	}
+}
+
+func TestWriteToRetries(t *testing.T) {
+	f := newFakeS3()
+	keys, all := fakeMembers(f, 100, 50)
+	var calls atomic.Int32
+	f.onGet = func(context.Context, *s3.GetObjectInput) error {
+		if calls.Add(1) == 1 {
+			return &smithy.GenericAPIError{Code: "InternalError"}
+		}
+		return nil
+	}
+	rs, err := NewS3ReadSeeker(f, testBucket, keys, WithRetryConfig(RetryConfig{MaxAttempts: 3}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	var buf bytes.Buffer
+	if n, err := rs.WriteTo(&buf); err != nil || !bytes.Equal(buf.Bytes(), all) {
+		t.Fatalf("WriteTo = %d, %v, want all %d bytes", n, err, len(all))
+	}
+	if got := f.getCount(); got != len(keys)+1 {
+		t.Errorf("GetObjects = %d, want %d", got, len(keys)+1)
+	}
+}
+
+func TestMembersInSeveralBuckets(t *testing.T) {
+	f := newFakeS3()
+	// the same keys in both buckets, of different sizes and content, so
+	// that a HeadObject or GetObject routed to the wrong bucket shows
+	var specs []ObjectSpec
+	var all []byte
+	for i := 0; i < 3; i++ {
+		for j, bucket := range []string{"bucket-a", "bucket-b"} {
+			key := fmt.Sprintf("part-%d", i)
+			data := bytes.Repeat([]byte(bucket+key), 10+i+5*j)
+			f.putIn(bucket, key, data)
+			specs = append(specs, ObjectSpec{Bucket: bucket, Key: key, Size: UnknownSize})
+			all = append(all, data...)
+		}
+	}
+	// an empty Bucket defaults to the constructor's
+	specs[0].Bucket = ""
+	rs, err := NewS3ReadSeekerFromSpecs(f, "bucket-a", specs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	got, err := io.ReadAll(rs)
+	if err != nil || !bytes.Equal(got, all) {
+		t.Fatalf("ReadAll = %q, %v, want %q", got, err, all)
+	}
+	specs = append(specs, ObjectSpec{Bucket: "bucket-b", Key: "missing", Size: 10})
+	rs, err = NewS3ReadSeekerFromSpecs(f, "bucket-a", specs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	if _, err := rs.ReadAt(make([]byte, 10), int64(len(all))); err == nil || !strings.Contains(err.Error(), "bucket-b/missing") {
+		t.Errorf("ReadAt of a missing member = %v, want an error naming bucket-b/missing", err)
+	}
+}
+
+func TestCopyIssuesOneGetObjectPerMember(t *testing.T) {
+	f := newFakeS3()
+	keys, all := fakeMembers(f, 100<<10, 0, 70<<10, 5)
+	rs, err := NewS3ReadSeeker(f, testBucket, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	if _, err := rs.Seek(50, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, rs)
+	if err != nil || n != int64(len(all))-50 || !bytes.Equal(buf.Bytes(), all[50:]) {
+		t.Fatalf("io.Copy = %d, %v, want the %d bytes from 50", n, err, len(all)-50)
+	}
+	// one request for each non-empty member, far fewer than copy buffers
+	if got := f.getCount(); got != len(keys)-1 {
+		t.Errorf("GetObjects = %d, want %d", got, len(keys)-1)
+	}
+	if got := aws.ToString(f.gets[0].Range); got != "bytes=50-" {
+		t.Errorf("Range of the first GetObject = %q, want bytes=50-", got)
+	}
+	if off := rs.Offset(); off != int64(len(all)) {
+		t.Errorf("Offset() after io.Copy = %d, want %d", off, len(all))
+	}
+}
+
+func TestObjectReadAtPastEnd(t *testing.T) {
+	f := newFakeS3()
+	keys, all := fakeMembers(f, 100)
+	rs, err := NewS3ReadSeeker(f, testBucket, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+	members, _ := rs.members()
+	p := make([]byte, 50)
+	n, err := members[0].ReadAt(p, 80)
+	if n != 20 || err != io.EOF || !bytes.Equal(p[:n], all[80:]) {
+		t.Fatalf("ReadAt(50 bytes, 80) of 100 bytes = %d, %v, want 20, io.EOF", n, err)
+	}
+	if got := aws.ToString(f.gets[len(f.gets)-1].Range); got != "bytes=80-99" {
+		t.Errorf("Range = %q, want bytes=80-99", got)
+	}
+}
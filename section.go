@@ -0,0 +1,144 @@
+package s3ReadSeeker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Section is a window of the stream of an S3ReadSeeker with offsets
+// relative to its start, e.g. one logical file within the concatenation.
+// It reads through the seeker's members, range cache and request
+// limits, but has its own offset, and reports io.EOF at the end of the
+// window even where the stream continues. A Section is safe for
+// concurrent use, also with its seeker, and must not be used after the
+// seeker is closed.
+type Section struct {
+	s    *S3ReadSeeker
+	base int64
+	n    int64
+
+	mu  sync.Mutex
+	off int64
+}
+
+var (
+	_ io.ReadSeeker = (*Section)(nil)
+	_ io.ReaderAt   = (*Section)(nil)
+	_ io.WriterTo   = (*Section)(nil)
+)
+
+// Section returns the section of the length bytes of the stream starting
+// at off. A section extending past the end of the stream fails with
+// ErrOffsetOutOfRange.
+func (s *S3ReadSeeker) Section(off, length int64) (*Section, error) {
+	if s.closed.Load() {
+		return nil, ErrClosed
+	}
+	if off < 0 {
+		return nil, fmt.Errorf("%w: %d", ErrNegativeOffset, off)
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("invalid length: %d", length)
+	}
+	if err := s.resolveUntil(s.context(), off+length); err != nil {
+		return nil, err
+	}
+	if _, ends := s.resolvedMembers(); off+length > totalSize(ends) {
+		return nil, fmt.Errorf("%w: %d bytes at %d beyond %d bytes", ErrOffsetOutOfRange, length, off, totalSize(ends))
+	}
+	return &Section{s: s, base: off, n: length}, nil
+}
+
+// Size returns the length of the section.
+func (r *Section) Size() int64 {
+	return r.n
+}
+
+func (r *Section) Read(p []byte) (n int, err error) {
+	return r.ReadContext(r.s.context(), p)
+}
+
+// ReadContext is like Read but issues any S3 requests with ctx.
+func (r *Section) ReadContext(ctx context.Context, p []byte) (n int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n, err = r.ReadAtContext(ctx, p, r.off)
+	r.off += int64(n)
+	if err == io.EOF && n > 0 {
+		// like os.File, report io.EOF on the next Read
+		err = nil
+	}
+	return n, err
+}
+
+// ReadAt implements io.ReaderAt, with off relative to the start of the
+// section. It does not use or move the current offset.
+func (r *Section) ReadAt(p []byte, off int64) (n int, err error) {
+	return r.ReadAtContext(r.s.context(), p, off)
+}
+
+// ReadAtContext is like ReadAt but issues any S3 requests with ctx.
+func (r *Section) ReadAtContext(ctx context.Context, p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, fmt.Errorf("%w: %d", ErrNegativeOffset, off)
+	}
+	if off >= r.n {
+		return 0, io.EOF
+	}
+	if rest := r.n - off; int64(len(p)) > rest {
+		n, err = r.s.ReadAtContext(ctx, p[:rest], r.base+off)
+		if err == nil {
+			err = io.EOF
+		}
+		return n, err
+	}
+	return r.s.ReadAtContext(ctx, p, r.base+off)
+}
+
+// Seek sets the offset within the section for the next Read. Seeking past
+// the end of the section fails with ErrOffsetOutOfRange.
+func (r *Section) Seek(offset int64, whence int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var newOffset int64
+	switch whence {
+	case io.SeekStart:
+		newOffset = offset
+	case io.SeekCurrent:
+		newOffset = r.off + offset
+	case io.SeekEnd:
+		newOffset = r.n + offset
+	default:
+		return 0, fmt.Errorf("invalid whence: %d", whence)
+	}
+	switch {
+	case newOffset < 0:
+		return 0, fmt.Errorf("%w: %d", ErrNegativeOffset, newOffset)
+	case newOffset > r.n:
+		return 0, fmt.Errorf("%w: %d beyond %d bytes", ErrOffsetOutOfRange, newOffset, r.n)
+	}
+	r.off = newOffset
+	return r.off, nil
+}
+
+// WriteTo implements io.WriterTo. It streams the rest of the section from
+// the current offset through a RangeReader, one GetObject per member, and
+// advances the offset by the bytes written, also when an error is
+// returned.
+func (r *Section) WriteTo(w io.Writer) (n int64, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.off >= r.n {
+		return 0, nil
+	}
+	rr, err := r.s.RangeReader(r.s.context(), r.base+r.off, r.n-r.off)
+	if err != nil {
+		return 0, err
+	}
+	defer rr.Close()
+	n, err = io.Copy(w, rr)
+	r.off += n
+	return n, err
+}
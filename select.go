@@ -0,0 +1,130 @@
+package s3ReadSeeker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3SelectAPI is an S3API that can also run S3 Select queries. *s3.Client
+// implements it.
+type S3SelectAPI interface {
+	S3API
+	SelectObjectContent(ctx context.Context, params *s3.SelectObjectContentInput, optFns ...func(*s3.Options)) (*s3.SelectObjectContentOutput, error)
+}
+
+var _ S3SelectAPI = (*s3.Client)(nil)
+
+// Select runs the S3 Select query described by input against each member
+// in turn and returns a reader of the concatenated result records. input
+// supplies the expression and the input and output serializations; its
+// Bucket, Key and SSE-C fields are set for each member. The query is a
+// separate read path: it does not use or move the current offset, and the
+// returned reader cannot seek. The seeker's client must implement
+// S3SelectAPI.
+func (s *S3ReadSeeker) Select(ctx context.Context, input *s3.SelectObjectContentInput) (io.ReadCloser, error) {
+	client, ok := s.client.(S3SelectAPI)
+	if !ok {
+		return nil, errors.New("client does not support SelectObjectContent")
+	}
+	members, _ := s.members()
+	return &selectReader{ctx: ctx, client: client, input: input, members: members}, nil
+}
+
+// selectReader reads the records of an S3 Select query over members.
+type selectReader struct {
+	ctx     context.Context
+	client  S3SelectAPI
+	input   *s3.SelectObjectContentInput
+	members []*Object
+
+	// stream is the result of members[next-1], if open, and ended is set
+	// once it has delivered its end event
+	next    int
+	stream  *s3.SelectObjectContentEventStream
+	ended   bool
+	records []byte
+}
+
+func (r *selectReader) Read(p []byte) (int, error) {
+	for len(r.records) == 0 {
+		if r.stream == nil {
+			if r.next == len(r.members) {
+				return 0, io.EOF
+			}
+			if err := r.open(r.members[r.next]); err != nil {
+				return 0, err
+			}
+			r.next++
+			continue
+		}
+		event, ok := <-r.stream.Events()
+		if !ok {
+			if err := r.closeStream(); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		switch e := event.(type) {
+		case *types.SelectObjectContentEventStreamMemberRecords:
+			r.records = e.Value.Payload
+		case *types.SelectObjectContentEventStreamMemberEnd:
+			r.ended = true
+		}
+	}
+	n := copy(p, r.records)
+	r.records = r.records[n:]
+	return n, nil
+}
+
+// open starts the query on obj.
+func (r *selectReader) open(obj *Object) error {
+	input := *r.input
+	input.Bucket = aws.String(obj.bucketName)
+	input.Key = aws.String(obj.key)
+	if obj.start > 0 {
+		input.ScanRange = &types.ScanRange{Start: aws.Int64(obj.start)}
+	}
+	if sse := obj.sseCustomerKey; sse != nil {
+		input.SSECustomerAlgorithm = aws.String(sse.algorithm)
+		input.SSECustomerKey = aws.String(sse.key)
+		input.SSECustomerKeyMD5 = aws.String(sse.keyMD5)
+	}
+	output, err := r.client.SelectObjectContent(r.ctx, &input, obj.opts.s3Options...)
+	if err != nil {
+		return fmt.Errorf("select object content %s: %w", obj.path(), err)
+	}
+	r.stream, r.ended = output.GetStream(), false
+	return nil
+}
+
+// closeStream closes the stream of the current member, reporting an error
+// if it failed or ended without its end event.
+func (r *selectReader) closeStream() error {
+	obj := r.members[r.next-1]
+	err := r.stream.Err()
+	r.stream.Close()
+	r.stream = nil
+	if err != nil {
+		return fmt.Errorf("select object content %s: %w", obj.path(), err)
+	}
+	if !r.ended {
+		return fmt.Errorf("select object content %s: %w", obj.path(), io.ErrUnexpectedEOF)
+	}
+	return nil
+}
+
+// Close stops the query.
+func (r *selectReader) Close() error {
+	if r.stream != nil {
+		r.stream.Close()
+		r.stream = nil
+	}
+	r.next = len(r.members)
+	return nil
+}
@@ -0,0 +1,75 @@
+package s3ReadSeeker
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
+)
+
+// shared holds the configuration and state shared by a seeker and all of
+// its members.
+type shared struct {
+	opts  options
+	cache *blockCache
+	// flights is nil unless WithDeduplication is given
+	flights *flightGroup
+	sem     *semaphore.Weighted
+	// limiter is nil unless WithBandwidthLimit is given
+	limiter *rate.Limiter
+	clock   clock
+	stats   counters
+	// tracer is nil unless WithTracerProvider is given
+	tracer trace.Tracer
+}
+
+func newShared(opts []Option) (*shared, error) {
+	sh := &shared{opts: defaultOptions(), clock: realClock{}}
+	for _, opt := range opts {
+		if err := opt(&sh.opts); err != nil {
+			return nil, err
+		}
+	}
+	if sh.opts.streaming && sh.opts.readAheadSize > 0 {
+		return nil, errors.New("WithStreaming cannot be combined with WithReadAheadSize or WithReadAhead")
+	}
+	switch {
+	case sh.opts.cacheSize > 0:
+		sh.cache = newBlockCache(sh.opts.cacheBlockSize, sh.opts.cacheSize)
+	case sh.opts.minFetchSize > 0:
+		// a cache of a single block
+		sh.cache = newBlockCache(sh.opts.minFetchSize, sh.opts.minFetchSize)
+	}
+	if sh.opts.dedupe {
+		sh.flights = newFlightGroup()
+	}
+	if sh.opts.maxInFlight > 0 {
+		sh.sem = semaphore.NewWeighted(sh.opts.maxInFlight)
+	}
+	if sh.opts.bandwidthLimit > 0 {
+		sh.limiter = rate.NewLimiter(rate.Limit(sh.opts.bandwidthLimit), sh.opts.bandwidthLimit)
+	}
+	if sh.opts.tracerProvider != nil {
+		sh.tracer = sh.opts.tracerProvider.Tracer(tracerName)
+	}
+	return sh, nil
+}
+
+// acquire waits for a request slot, see WithMaxInFlightRequests, and
+// counts the request as in flight. The returned func releases the slot.
+func (sh *shared) acquire(ctx context.Context) (release func(), err error) {
+	if sh.sem != nil {
+		if err := sh.sem.Acquire(ctx, 1); err != nil {
+			return nil, err
+		}
+	}
+	sh.stats.inFlight.Add(1)
+	return func() {
+		sh.stats.inFlight.Add(-1)
+		if sh.sem != nil {
+			sh.sem.Release(1)
+		}
+	}, nil
+}
@@ -0,0 +1,55 @@
+package s3ReadSeeker
+
+import (
+	"context"
+	"io"
+)
+
+// isSmall reports whether o is kept in memory in full, see
+// WithSmallObjectThreshold.
+func (o *Object) isSmall() bool {
+	return o.opts.smallObjectThreshold > 0 && o.size <= o.opts.smallObjectThreshold
+}
+
+// readSmall serves a read from the in-memory copy of o, fetching it on
+// first use.
+func (o *Object) readSmall(ctx context.Context, p []byte, off int64) (n int, err error) {
+	data, err := o.wholeObject(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if off >= int64(len(data)) {
+		return 0, &SizeMismatchError{Bucket: o.bucketName, Key: o.key, Size: o.size, Offset: off, Err: io.ErrUnexpectedEOF}
+	}
+	return copy(p, data[off:]), nil
+}
+
+// wholeObject returns the content of o, fetching it with a GetObject
+// without a range the first time. Concurrent first reads wait for a single
+// fetch, and a failed fetch is retried by the next read.
+func (o *Object) wholeObject(ctx context.Context) ([]byte, error) {
+	o.dataMu.Lock()
+	defer o.dataMu.Unlock()
+	if o.data != nil {
+		return o.data, nil
+	}
+	if err := o.allow(); err != nil {
+		return nil, err
+	}
+	data := make([]byte, o.size)
+	err := o.retry(ctx, func(attempt int) error {
+		ctx, cancel := o.opts.requestContext(ctx)
+		defer cancel()
+		ctx, span := o.startSpan(ctx, "GetObject", 0, o.size, attempt)
+		n, err := o.readRange(ctx, data, 0, o.rangeFrom(0))
+		endSpan(span, int64(n), err)
+		return err
+	})
+	o.record(ctx, err)
+	if err != nil {
+		return nil, err
+	}
+	o.data = data
+	o.stats.smallObjectBytes.Add(int64(len(data)))
+	return data, nil
+}
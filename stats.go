@@ -0,0 +1,90 @@
+package s3ReadSeeker
+
+import "sync/atomic"
+
+// Stats is a snapshot of the request and byte counters of an S3ReadSeeker.
+type Stats struct {
+	// GetRequests and HeadRequests count the GetObject and HeadObject
+	// calls issued, including retries.
+	GetRequests  int64
+	HeadRequests int64
+	// BytesFetched counts the bytes received in GetObject bodies.
+	BytesFetched int64
+	// BytesReturned counts the bytes handed to the caller by Read, ReadAt
+	// and WriteTo.
+	BytesReturned int64
+	// Retries counts the requests retried after a transient error.
+	Retries int64
+	// CacheHits and CacheMisses count the blocks looked up in the cache
+	// of WithRangeCache or WithBlockCache, or the block kept by
+	// WithMinFetchSize, that were and were not found.
+	CacheHits   int64
+	CacheMisses int64
+	// DedupedRequests counts the reads that joined a GetObject already in
+	// progress instead of issuing their own, see WithDeduplication. They
+	// are not included in GetRequests.
+	DedupedRequests int64
+	// HedgedRequests counts the second requests issued by WithHedging,
+	// which are included in GetRequests, and HedgeWins those of them
+	// that completed first.
+	HedgedRequests int64
+	HedgeWins      int64
+	// InFlightRequests is the number of GetObject and HeadObject requests
+	// currently in flight, including open streamed bodies, see
+	// WithMaxInFlightRequests, and
+	// SmallObjectBytes the memory held by the members kept in full by
+	// WithSmallObjectThreshold. Unlike the counters above, these gauges
+	// are not reset by ResetStats.
+	InFlightRequests int64
+	SmallObjectBytes int64
+}
+
+type counters struct {
+	getRequests     atomic.Int64
+	headRequests    atomic.Int64
+	bytesFetched    atomic.Int64
+	bytesReturned   atomic.Int64
+	retries         atomic.Int64
+	cacheHits       atomic.Int64
+	cacheMisses     atomic.Int64
+	dedupedRequests atomic.Int64
+	hedgedRequests  atomic.Int64
+	hedgeWins       atomic.Int64
+	// inFlight and smallObjectBytes are gauges rather than counters
+	inFlight         atomic.Int64
+	smallObjectBytes atomic.Int64
+}
+
+// Stats returns a snapshot of the seeker's counters. It is safe to call
+// concurrently with reads.
+func (s *S3ReadSeeker) Stats() Stats {
+	return Stats{
+		GetRequests:      s.stats.getRequests.Load(),
+		HeadRequests:     s.stats.headRequests.Load(),
+		BytesFetched:     s.stats.bytesFetched.Load(),
+		BytesReturned:    s.stats.bytesReturned.Load(),
+		Retries:          s.stats.retries.Load(),
+		CacheHits:        s.stats.cacheHits.Load(),
+		CacheMisses:      s.stats.cacheMisses.Load(),
+		DedupedRequests:  s.stats.dedupedRequests.Load(),
+		HedgedRequests:   s.stats.hedgedRequests.Load(),
+		HedgeWins:        s.stats.hedgeWins.Load(),
+		InFlightRequests: s.stats.inFlight.Load(),
+		SmallObjectBytes: s.stats.smallObjectBytes.Load(),
+	}
+}
+
+// ResetStats sets all counters to zero. The gauges InFlightRequests and
+// SmallObjectBytes are kept.
+func (s *S3ReadSeeker) ResetStats() {
+	s.stats.getRequests.Store(0)
+	s.stats.headRequests.Store(0)
+	s.stats.bytesFetched.Store(0)
+	s.stats.bytesReturned.Store(0)
+	s.stats.retries.Store(0)
+	s.stats.cacheHits.Store(0)
+	s.stats.cacheMisses.Store(0)
+	s.stats.dedupedRequests.Store(0)
+	s.stats.hedgedRequests.Store(0)
+	s.stats.hedgeWins.Store(0)
+}
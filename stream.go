@@ -0,0 +1,158 @@
+package s3ReadSeeker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// openStream opens a GetObject body of o from off to its end, see
+// WithStreaming. The caller must hold the seeker's mu.
+func (o *Object) openStream(ctx context.Context, off int64) error {
+	byteRange := o.rangeFrom(off)
+	result, err := o.openBody(ctx, off, o.size-off, byteRange, false)
+	if err != nil {
+		return err
+	}
+	o.body, o.bodyOffset = result.Body, off
+	o.bodyRange, o.bodyMetadata = byteRange, result.ResultMetadata
+	return nil
+}
+
+// openBody issues a GetObject of the n bytes of o at off, whose Range
+// header is byteRange, and returns the response with its body unread. The
+// request keeps its slot, see WithMaxInFlightRequests, until the body is
+// closed. If timed is set, WithRequestTimeout bounds the request up to
+// then as well.
+func (o *Object) openBody(ctx context.Context, off, n int64, byteRange string, timed bool) (result *s3.GetObjectOutput, err error) {
+	if o.archived != nil {
+		return nil, o.archived
+	}
+	if err := o.allow(); err != nil {
+		return nil, err
+	}
+	defer func() { o.record(ctx, err) }()
+	input := o.getObjectInput(byteRange)
+	var release func()
+	err = o.retry(ctx, func(attempt int) (err error) {
+		reqCtx, cancel := ctx, context.CancelFunc(func() {})
+		if timed {
+			reqCtx, cancel = o.opts.requestContext(ctx)
+		}
+		reqCtx, span := o.startSpan(reqCtx, "GetObject", off, n, attempt)
+		start := time.Now()
+		defer func() {
+			o.reportRequest(reqCtx, "GetObject", byteRange, start, result, 0, err)
+			endSpan(span, 0, err)
+		}()
+		slot, err := o.acquire(reqCtx)
+		if err != nil {
+			cancel()
+			return fmt.Errorf("get object %s: %w", o.path(), err)
+		}
+		defer func() {
+			if err != nil {
+				slot()
+				cancel()
+			} else {
+				release = func() {
+					slot()
+					cancel()
+				}
+			}
+		}()
+		o.stats.getRequests.Add(1)
+		result, err = o.client.GetObject(reqCtx, input, o.opts.s3Options...)
+		if err != nil {
+			if reqCtx.Err() != nil {
+				return fmt.Errorf("get object %s: %w", o.path(), context.Cause(reqCtx))
+			}
+			return fmt.Errorf("get object %s: %w", o.path(), o.getObjectError(err, off, byteRange))
+		}
+		if err := o.checkContentRange(byteRange, result); err != nil {
+			result.Body.Close()
+			return o.bodyError(err, byteRange, result.ResultMetadata)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Body = &slotBody{ReadCloser: result.Body, release: release}
+	return result, nil
+}
+
+// slotBody is a GetObject body that releases the request's slot once
+// closed.
+type slotBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *slotBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// readStream reads into p from the current offset through the open body
+// of the member the offset falls in, first opening one from the offset to
+// the end of that member if needed. The body is reopened when ctx differs
+// from the one it was opened with, which may since have been canceled. A
+// read never spans members. The caller must hold mu.
+func (s *S3ReadSeeker) readStream(ctx context.Context, p []byte) (n int, err error) {
+	if len(p) == 0 || s.closed.Load() {
+		return s.readAt(ctx, p, s.globalOffset)
+	}
+	if err := s.resolveUntil(ctx, s.globalOffset+1); err != nil {
+		return 0, err
+	}
+	members, ends := s.resolvedMembers()
+	i := sort.Search(len(ends), func(i int) bool { return ends[i] > s.globalOffset })
+	if i == len(members) {
+		return 0, io.EOF
+	}
+	obj := members[i]
+	off := s.globalOffset - (ends[i] - obj.size)
+	if s.stream != obj || obj.bodyOffset != off || s.streamCtx != ctx {
+		s.closeStream()
+		bodyCtx, stop := s.bind(ctx)
+		if err := obj.openStream(bodyCtx, off); err != nil {
+			stop()
+			return 0, err
+		}
+		s.stream, s.streamCtx, s.streamStop = obj, ctx, stop
+	}
+	n, err = io.ReadAtLeast(obj.limitBody(ctx, obj.body), p[:min(int64(len(p)), obj.size-off)], 1)
+	s.stats.bytesFetched.Add(int64(n))
+	obj.bodyOffset += int64(n)
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		err = obj.bodyError(&SizeMismatchError{Bucket: obj.bucketName, Key: obj.key, Size: obj.size, Offset: obj.bodyOffset, Err: err}, obj.bodyRange, obj.bodyMetadata)
+		s.closeStream()
+		return n, err
+	case err != nil:
+		err = obj.bodyError(err, obj.bodyRange, obj.bodyMetadata)
+		s.closeStream()
+		return n, err
+	case obj.bodyOffset == obj.size:
+		s.closeStream()
+	}
+	return n, nil
+}
+
+// closeStream closes the open body, if any. The caller must hold mu.
+func (s *S3ReadSeeker) closeStream() {
+	if s.stream != nil {
+		s.stream.body.Close()
+		s.stream.body = nil
+		s.streamStop()
+		s.stream, s.streamCtx, s.streamStop = nil, nil, nil
+	}
+}
@@ -0,0 +1,42 @@
+package s3ReadSeeker
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/zing22845/s3readseeker"
+
+// startSpan starts a span named after op for a request for length bytes of
+// o at off. It returns a nil span if tracing is disabled.
+func (o *Object) startSpan(ctx context.Context, op string, off, length int64, attempt int) (context.Context, trace.Span) {
+	if o.tracer == nil {
+		return ctx, nil
+	}
+	return o.tracer.Start(ctx, "s3readseeker."+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("s3.bucket", o.bucketName),
+			attribute.String("s3.key", o.key),
+			attribute.Int64("s3readseeker.range.start", off),
+			attribute.Int64("s3readseeker.range.length", length),
+			attribute.Int("s3readseeker.attempt", attempt),
+		),
+	)
+}
+
+// endSpan records the outcome of a request on span, which may be nil.
+func endSpan(span trace.Span, bytes int64, err error) {
+	if span == nil {
+		return
+	}
+	span.SetAttributes(attribute.Int64("s3readseeker.bytes", bytes))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}